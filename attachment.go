@@ -1,21 +1,60 @@
 package gostmark
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"sync"
 )
 
+// forbiddenExtensions are the file extensions
+// Postmark refuses to send as attachments
+var forbiddenExtensions = map[string]bool{
+	"vbs": true, "exe": true, "bin": true, "bat": true, "chm": true,
+	"com": true, "cpl": true, "crt": true, "hlp": true, "hta": true,
+	"inf": true, "ins": true, "isp": true, "jse": true, "lnk": true,
+	"mdb": true, "pcd": true, "pif": true, "reg": true, "scr": true,
+	"sct": true, "shs": true, "vbe": true, "vba": true, "wsf": true,
+	"wsh": true, "wsl": true, "msc": true, "msi": true, "msp": true,
+	"mst": true,
+}
+
+// errAttachmentConsumed is returned when an attachment has
+// to be read a second time, e.g. for a retry, but its Reader
+// cannot be rewound
+var errAttachmentConsumed = errors.New("attachment Reader was already consumed and cannot be rewound")
+
+var errAttachmentNoReader = errors.New("attachment Reader required")
+
 type Attachment struct {
 	Name        string
 	ContentType string
 	Reader      io.Reader
 	ContentID   string
 
+	// Size is the length of Reader's content before encoding.
+	// It lets the 10MB limit be checked before sending; when
+	// zero it is taken from the Reader where possible.
+	Size int64
+
 	contents string
 
+	// read is set once Reader has been read from,
+	// and offset is where a seekable Reader started
+	read   bool
+	offset int64
+
 	sync.Mutex
 }
 
@@ -28,49 +67,391 @@ func NewAttachment(name, contentType string, reader io.Reader) *Attachment {
 	}
 }
 
-// Contents returns the file contents. Currently
-// non-streaming, and memory-caching, so hardly
-// efficient.
+// AttachmentFromBytes returns an attachment of b, detecting
+// its content type from the extension of name or, failing
+// that, from the content itself
+func AttachmentFromBytes(name string, b []byte) (*Attachment, error) {
+	if err := checkAttachmentName(name); err != nil {
+		return nil, err
+	}
+
+	return &Attachment{
+		Name:        name,
+		ContentType: detectContentType(name, b),
+		Reader:      bytes.NewReader(b),
+		Size:        int64(len(b)),
+	}, nil
+}
+
+// AttachmentFromFile returns an attachment of the named file,
+// detecting its content type as AttachmentFromBytes does. The
+// file is read as the message is sent rather than up front,
+// so it is kept open until Close is called.
+func AttachmentFromFile(filename string) (*Attachment, error) {
+	name := filepath.Base(filename)
+	if err := checkAttachmentName(name); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := attachmentFromFile(name, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// AttachmentFromFS returns an attachment of the file at
+// filePath in fsys, such as an embed.FS. Files that cannot
+// seek are read into memory and closed straight away;
+// otherwise the file is kept open until Close is called.
+func AttachmentFromFS(fsys fs.FS, filePath string) (*Attachment, error) {
+	name := path.Base(filePath)
+	if err := checkAttachmentName(name); err != nil {
+		return nil, err
+	}
+
+	f, err := fsys.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := attachmentFromFile(name, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// attachmentFromFile wraps an open file, which
+// the attachment takes ownership of on success
+func attachmentFromFile(name string, f fs.File) (*Attachment, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("attachment %q is a directory", name)
+	}
+
+	file, seekable := f.(io.ReadSeeker)
+	if !seekable {
+		b, err := ioutil.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		f.Close()
+		return AttachmentFromBytes(name, b)
+	}
+
+	// Only sniff the content when the
+	// extension does not give it away
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		head := make([]byte, 512)
+		n, err := io.ReadFull(file, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		contentType = http.DetectContentType(head[:n])
+	}
+
+	return &Attachment{
+		Name:        name,
+		ContentType: contentType,
+		Reader:      file,
+		Size:        info.Size(),
+	}, nil
+}
+
+// detectContentType returns the content type for name by
+// its extension, sniffing content when that is unknown
+func detectContentType(name string, content []byte) string {
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		return contentType
+	}
+
+	return http.DetectContentType(content)
+}
+
+// checkAttachmentName returns an error if Postmark
+// will refuse an attachment with this file name
+func checkAttachmentName(name string) error {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+	if forbiddenExtensions[ext] {
+		return fmt.Errorf("attachment %q: .%s files cannot be sent through Postmark", name, ext)
+	}
+
+	return nil
+}
+
+// Close closes the attachment's Reader if it is an io.Closer,
+// such as the file opened by AttachmentFromFile. Call it once
+// the message has been sent.
+func (a *Attachment) Close() error {
+	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+
+	if closer, ok := a.Reader.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// Contents returns the base64 encoded file contents. It
+// reads the whole Reader into memory and caches the result
+// for the life of the attachment. Sending and marshalling
+// do not use it; they encode straight from the Reader.
 func (a *Attachment) Contents() (string, error) {
 	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+
 	if len(a.contents) == 0 {
-		b, e := ioutil.ReadAll(a.Reader)
-		if e != nil {
-			return "", e
-		} else {
-			b64 := base64.StdEncoding.EncodeToString(b)
-			a.contents = b64
+		reader, err := a.rewind()
+		if err != nil {
+			return "", err
+		}
+
+		b, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return "", err
 		}
+		a.contents = base64.StdEncoding.EncodeToString(b)
 	}
-	a.Mutex.Unlock()
+
 	return a.contents, nil
 }
 
+// rewind returns the Reader positioned at the start of the
+// content, seeking back if it has been read before. The
+// caller must hold the mutex.
+func (a *Attachment) rewind() (io.Reader, error) {
+	if a.Reader == nil {
+		return nil, errAttachmentNoReader
+	}
+
+	seeker, seekable := a.Reader.(io.Seeker)
+	switch {
+	case !a.read && seekable:
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		a.offset = offset
+	case a.read && seekable:
+		if _, err := seeker.Seek(a.offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	case a.read:
+		return nil, errAttachmentConsumed
+	}
+
+	a.read = true
+	return a.Reader, nil
+}
+
+// contentSize returns the length of the content before
+// encoding, and false if it cannot be known without
+// reading it
+func (a *Attachment) contentSize() (int64, bool) {
+	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+
+	if a.Size > 0 {
+		return a.Size, true
+	}
+	if a.contents != "" {
+		return int64(base64.StdEncoding.DecodedLen(len(a.contents))), true
+	}
+
+	// Seekers are measured from where they started, so
+	// the size holds after the attachment has been sent;
+	// Len only reports what is left to read
+	switch r := a.Reader.(type) {
+	case io.Seeker:
+		current, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		end, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, false
+		}
+		if _, err := r.Seek(current, io.SeekStart); err != nil {
+			return 0, false
+		}
+		if a.read {
+			current = a.offset
+		}
+		return end - current, true
+	case interface{ Len() int }:
+		if a.read {
+			return 0, false
+		}
+		return int64(r.Len()), true
+	default:
+		return 0, false
+	}
+}
+
+// rewindable reports whether the content can
+// be written more than once, as a retry needs
+func (a *Attachment) rewindable() bool {
+	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+
+	if a.contents != "" {
+		return true
+	}
+	_, seekable := a.Reader.(io.Seeker)
+	return seekable
+}
+
+// readable returns why the content cannot be
+// written, or nil if writing it can begin
+func (a *Attachment) readable() error {
+	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+
+	if a.contents != "" {
+		return nil
+	}
+	if a.Reader == nil {
+		return errAttachmentNoReader
+	}
+	if _, seekable := a.Reader.(io.Seeker); a.read && !seekable {
+		return errAttachmentConsumed
+	}
+
+	return nil
+}
+
+// encodedSize returns the length of the base64 content
+func (a *Attachment) encodedSize() (int64, bool) {
+	a.Mutex.Lock()
+	contents := a.contents
+	a.Mutex.Unlock()
+	if contents != "" {
+		return int64(len(contents)), true
+	}
+
+	size, ok := a.contentSize()
+	if !ok {
+		return 0, false
+	}
+
+	return int64(base64.StdEncoding.EncodedLen(int(size))), true
+}
+
+// writeContent base64 encodes the content into w. Cached
+// contents are reused, otherwise the Reader is streamed
+// through the encoder without being held in memory.
+func (a *Attachment) writeContent(w io.Writer) error {
+	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+
+	if a.contents != "" {
+		_, err := io.WriteString(w, a.contents)
+		return err
+	}
+
+	reader, err := a.rewind()
+	if err != nil {
+		return err
+	}
+
+	encoder := base64.NewEncoder(base64.StdEncoding, w)
+	if _, err := io.Copy(encoder, reader); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// jsonHeader returns the attachment's JSON up to the
+// opening quote of its Content, so the content can be
+// written after it followed by jsonTrailer
+func (a *Attachment) jsonHeader() ([]byte, error) {
+	packet := struct {
+		Name        string
+		ContentType string
+		ContentID   string `json:",omitempty"`
+	}{
+		Name:        a.Name,
+		ContentType: a.ContentType,
+		ContentID:   a.ContentID,
+	}
+
+	b, err := json.Marshal(&packet)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b[:len(b)-1], `,"Content":"`...), nil
+}
+
+// jsonTrailer closes an attachment opened by jsonHeader
+const jsonTrailer = `"}`
+
 // MarshalJSON exports the attachment as JSON
 // for sending to the server
 func (a *Attachment) MarshalJSON() ([]byte, error) {
-	fileContents, err := a.Contents()
+	header, err := a.jsonHeader()
 	if err != nil {
 		return []byte{}, err
 	}
 
-	packet := struct {
+	var b bytes.Buffer
+	if size, ok := a.encodedSize(); ok {
+		b.Grow(len(header) + int(size) + len(jsonTrailer))
+	}
+	b.Write(header)
+	if err := a.writeContent(&b); err != nil {
+		return []byte{}, err
+	}
+	b.WriteString(jsonTrailer)
+	return b.Bytes(), nil
+}
+
+// UnmarshalJSON reads an attachment as MarshalJSON
+// writes it, decoding Content into the Reader
+func (a *Attachment) UnmarshalJSON(b []byte) error {
+	var packet struct {
 		Name        string
 		ContentType string
 		Content     string
-		ContentID   *string `json:",omitempty"`
-	}{
-		Name:        a.Name,
-		ContentType: a.ContentType,
-		Content:     string(fileContents),
+		ContentID   string
+	}
+	if err := json.Unmarshal(b, &packet); err != nil {
+		return err
 	}
 
-	// Content ID - omit if empty
-	if a.ContentID != "" {
-		// Copy
-		cid := a.ContentID
-		packet.ContentID = &cid
+	content, err := base64.StdEncoding.DecodeString(packet.Content)
+	if err != nil {
+		return fmt.Errorf("attachment %q: %w", packet.Name, err)
 	}
 
-	return json.Marshal(&packet)
+	a.Mutex.Lock()
+	defer a.Mutex.Unlock()
+
+	a.Name = packet.Name
+	a.ContentType = packet.ContentType
+	a.ContentID = packet.ContentID
+	a.Reader = bytes.NewReader(content)
+	a.Size = int64(len(content))
+	a.contents = packet.Content
+	a.read = false
+	a.offset = 0
+	return nil
 }
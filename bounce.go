@@ -0,0 +1,274 @@
+package gostmark
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/themartorana/Gostmark/v2/raw"
+)
+
+type Bounce struct {
+	ID            int64
+	Type          BounceType
+	TypeCode      int
+	Name          string
+	Tag           string
+	MessageID     string
+	ServerID      int
+	MessageStream string
+	Description   string
+	Details       string
+	Email         string
+	From          string
+	BouncedAt     time.Time
+	DumpAvailable bool
+	Inactive      bool
+	CanActivate   bool
+	Subject       string
+	Content       string
+}
+
+type BounceResults struct {
+	TotalCount int
+	Bounces    []Bounce
+}
+
+// DeliveryStats summarizes the bounces on a server
+type DeliveryStats struct {
+	InactiveMails int
+	Bounces       []BounceCount
+}
+
+type BounceCount struct {
+	Name  string
+	Type  BounceType
+	Count int
+}
+
+// BounceSearchPacket filters GetBounces. Inactive is
+// a pointer so that both true and false can be
+// filtered on; nil matches either.
+type BounceSearchPacket struct {
+	Type          BounceType
+	Inactive      *bool
+	EmailFilter   string
+	Tag           string
+	MessageID     string
+	FromDate      time.Time
+	ToDate        time.Time
+	MessageStream string
+
+	Count  int
+	Offset int
+}
+
+// AsValues returns the search packet as url.Values
+func (bsp BounceSearchPacket) AsValues() url.Values {
+	vals := make(url.Values)
+	vals.Add("offset", strconv.Itoa(bsp.Offset))
+
+	if bsp.Count == 0 {
+		bsp.Count = 500
+	}
+	vals.Add("count", strconv.Itoa(bsp.Count))
+
+	if bsp.Type != "" {
+		vals.Add("type", string(bsp.Type))
+	}
+	if bsp.Inactive != nil {
+		vals.Add("inactive", strconv.FormatBool(*bsp.Inactive))
+	}
+	if bsp.EmailFilter != "" {
+		vals.Add("emailFilter", bsp.EmailFilter)
+	}
+	if bsp.Tag != "" {
+		vals.Add("tag", bsp.Tag)
+	}
+	if bsp.MessageID != "" {
+		vals.Add("messageID", bsp.MessageID)
+	}
+	if !bsp.FromDate.IsZero() {
+		vals.Add("fromdate", bsp.FromDate.Format(time.RFC3339))
+	}
+	if !bsp.ToDate.IsZero() {
+		vals.Add("todate", bsp.ToDate.Format(time.RFC3339))
+	}
+	if bsp.MessageStream != "" {
+		vals.Add("messagestream", bsp.MessageStream)
+	}
+
+	return vals
+}
+
+// GetDeliveryStats returns bounce counts by type
+// and the number of inactive recipients
+func (c Client) GetDeliveryStats() (DeliveryStats, error) {
+	return c.GetDeliveryStatsContext(context.Background())
+}
+
+// GetDeliveryStatsContext is GetDeliveryStats
+// with a caller-supplied context
+func (c Client) GetDeliveryStatsContext(ctx context.Context) (DeliveryStats, error) {
+	var ds DeliveryStats
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodGet,
+			Path:    "/deliverystats",
+			Headers: c.serverHeaders(),
+		},
+		&ds,
+	)
+	if err != nil {
+		return DeliveryStats{}, err
+	}
+
+	return ds, nil
+}
+
+// GetBounces searches the bounces on the server
+func (c Client) GetBounces(packet BounceSearchPacket) (BounceResults, error) {
+	return c.GetBouncesContext(context.Background(), packet)
+}
+
+// GetBouncesContext is GetBounces
+// with a caller-supplied context
+func (c Client) GetBouncesContext(ctx context.Context, packet BounceSearchPacket) (BounceResults, error) {
+	var br BounceResults
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodGet,
+			Path:    "/bounces",
+			Headers: c.serverHeaders(),
+			Query:   packet.AsValues(),
+		},
+		&br,
+	)
+	if err != nil {
+		return BounceResults{}, err
+	}
+
+	return br, nil
+}
+
+// GetBounce retrieves a single bounce by ID
+func (c Client) GetBounce(bounceID int64) (Bounce, error) {
+	return c.GetBounceContext(context.Background(), bounceID)
+}
+
+// GetBounceContext is GetBounce
+// with a caller-supplied context
+func (c Client) GetBounceContext(ctx context.Context, bounceID int64) (Bounce, error) {
+	var b Bounce
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method: http.MethodGet,
+			Path: fmt.Sprintf(
+				"/bounces/%d",
+				bounceID,
+			),
+			Headers: c.serverHeaders(),
+		},
+		&b,
+	)
+	if err != nil {
+		return Bounce{}, err
+	}
+
+	return b, nil
+}
+
+// GetBounceDump returns the raw SMTP source of the
+// bounce. Dumps are kept for 30 days.
+func (c Client) GetBounceDump(bounceID int64) (string, error) {
+	return c.GetBounceDumpContext(context.Background(), bounceID)
+}
+
+// GetBounceDumpContext is GetBounceDump
+// with a caller-supplied context
+func (c Client) GetBounceDumpContext(ctx context.Context, bounceID int64) (string, error) {
+	var dump struct {
+		Body string
+	}
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method: http.MethodGet,
+			Path: fmt.Sprintf(
+				"/bounces/%d/dump",
+				bounceID,
+			),
+			Headers: c.serverHeaders(),
+		},
+		&dump,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return dump.Body, nil
+}
+
+// ActivateBounce reactivates the recipient of a
+// bounce so that it can be sent to again
+func (c Client) ActivateBounce(bounceID int64) (Bounce, error) {
+	return c.ActivateBounceContext(context.Background(), bounceID)
+}
+
+// ActivateBounceContext is ActivateBounce
+// with a caller-supplied context
+func (c Client) ActivateBounceContext(ctx context.Context, bounceID int64) (Bounce, error) {
+	var activated struct {
+		Message string
+		Bounce  Bounce
+	}
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method: http.MethodPut,
+			Path: fmt.Sprintf(
+				"/bounces/%d/activate",
+				bounceID,
+			),
+			Headers: c.serverHeaders(),
+		},
+		&activated,
+	)
+	if err != nil {
+		return Bounce{}, err
+	}
+
+	return activated.Bounce, nil
+}
+
+// GetBouncedTags returns the tags of
+// messages that have bounced
+func (c Client) GetBouncedTags() ([]string, error) {
+	return c.GetBouncedTagsContext(context.Background())
+}
+
+// GetBouncedTagsContext is GetBouncedTags
+// with a caller-supplied context
+func (c Client) GetBouncedTagsContext(ctx context.Context) ([]string, error) {
+	var tags []string
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodGet,
+			Path:    "/bounces/tags",
+			Headers: c.serverHeaders(),
+		},
+		&tags,
+	)
+	if err != nil {
+		return []string{}, err
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,51 @@
+package gostmark
+
+// BounceType is the kind of bounce Postmark recorded
+type BounceType string
+
+const (
+	BounceTypeHardBounce              BounceType = "HardBounce"
+	BounceTypeTransient               BounceType = "Transient"
+	BounceTypeUnsubscribe             BounceType = "Unsubscribe"
+	BounceTypeSubscribe               BounceType = "Subscribe"
+	BounceTypeAutoResponder           BounceType = "AutoResponder"
+	BounceTypeAddressChange           BounceType = "AddressChange"
+	BounceTypeDnsError                BounceType = "DnsError"
+	BounceTypeSpamNotification        BounceType = "SpamNotification"
+	BounceTypeOpenRelayTest           BounceType = "OpenRelayTest"
+	BounceTypeUnknown                 BounceType = "Unknown"
+	BounceTypeSoftBounce              BounceType = "SoftBounce"
+	BounceTypeVirusNotification       BounceType = "VirusNotification"
+	BounceTypeChallengeVerification   BounceType = "ChallengeVerification"
+	BounceTypeBadEmailAddress         BounceType = "BadEmailAddress"
+	BounceTypeSpamComplaint           BounceType = "SpamComplaint"
+	BounceTypeManuallyDeactivated     BounceType = "ManuallyDeactivated"
+	BounceTypeUnconfirmed             BounceType = "Unconfirmed"
+	BounceTypeBlocked                 BounceType = "Blocked"
+	BounceTypeSMTPApiError            BounceType = "SMTPApiError"
+	BounceTypeInboundError            BounceType = "InboundError"
+	BounceTypeDMARCPolicy             BounceType = "DMARCPolicy"
+	BounceTypeTemplateRenderingFailed BounceType = "TemplateRenderingFailed"
+)
+
+// IsHard reports whether the bounce is permanent; Postmark
+// deactivates the recipient after a hard bounce
+func (bt BounceType) IsHard() bool {
+	switch bt {
+	case BounceTypeHardBounce, BounceTypeBadEmailAddress, BounceTypeSpamComplaint, BounceTypeManuallyDeactivated:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsSoft reports whether the bounce is temporary
+// and delivery may succeed on a later attempt
+func (bt BounceType) IsSoft() bool {
+	switch bt {
+	case BounceTypeTransient, BounceTypeSoftBounce, BounceTypeDnsError, BounceTypeAutoResponder:
+		return true
+	default:
+		return false
+	}
+}
@@ -1,9 +1,13 @@
 package gostmark
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/themartorana/Gostmark/v2/raw"
 )
@@ -13,26 +17,30 @@ type Client struct {
 
 	AccountToken string
 	ServerToken  string
+
+	httpClient *http.Client
+	timeout    time.Duration
+	retry      *RetryPolicy
 }
 
 const defaultHost string = "https://api.postmarkapp.com"
 
 // ClientForAPIKey returns a new client intialized
 // to the provided API key
-func ClientForAccountToken(accountToken string) Client {
+func ClientForAccountToken(accountToken string, opts ...Option) Client {
 	return Client{
 		AccountToken: accountToken,
 		Host:         defaultHost,
-	}
+	}.With(opts...)
 }
 
 // ClientForServerToken returns a new client intialized
 // to the provided Server API key
-func ClientForServerToken(serverToken string) Client {
+func ClientForServerToken(serverToken string, opts ...Option) Client {
 	return Client{
 		ServerToken: serverToken,
 		Host:        defaultHost,
-	}
+	}.With(opts...)
 }
 
 // servers is an internal container for
@@ -59,86 +67,142 @@ func (c Client) HostOrDefault() string {
 	return defaultHost
 }
 
+// transport returns the raw.Transport every
+// request from this client is sent through
+func (c Client) transport() raw.Transport {
+	return raw.Transport{
+		Host:       c.HostOrDefault(),
+		HTTPClient: c.httpClient,
+		Retry:      c.retry,
+	}
+}
+
+// withTimeout applies the client timeout, if
+// one was configured, to the supplied context
+func (c Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// do sends r through the client's transport and decodes
+// the JSON response into out, which may be nil
+func (c Client) do(ctx context.Context, r raw.Request, out interface{}) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	return raw.Do(ctx, c.transport(), r, out)
+}
+
+// serverHeaders authenticates a request with the server token
+func (c Client) serverHeaders() map[string]string {
+	return map[string]string{
+		"X-Postmark-Server-Token": c.ServerToken,
+	}
+}
+
+// accountHeaders authenticates a request with the account token
+func (c Client) accountHeaders() map[string]string {
+	return map[string]string{
+		"X-Postmark-Account-Token": c.AccountToken,
+	}
+}
+
+// pageValues returns the querystring shared
+// by endpoints that list entities in pages
+func pageValues(count, offset int) url.Values {
+	if count == 0 {
+		count = 500
+	}
+
+	return url.Values{
+		"count":  {strconv.Itoa(count)},
+		"offset": {strconv.Itoa(offset)},
+	}
+}
+
 // GetServerForToken retreives a server struct for
 // the server token supplied
 func (c Client) GetServerByToken(serverToken string) (Server, error) {
-	body, err := raw.ResponseFromPostmarkPost(
-		c.HostOrDefault(),
-		"/server",
-		map[string]string{
-			"X-Postmark-Server-Token": serverToken,
+	return c.GetServerByTokenContext(context.Background(), serverToken)
+}
+
+// GetServerByTokenContext is GetServerByToken
+// with a caller-supplied context
+func (c Client) GetServerByTokenContext(ctx context.Context, serverToken string) (Server, error) {
+	var s Server
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method: http.MethodGet,
+			Path:   "/server",
+			Headers: map[string]string{
+				"X-Postmark-Server-Token": serverToken,
+			},
 		},
-		nil,
+		&s,
 	)
 	if err != nil {
 		return Server{}, err
 	}
 
-	var s Server
-	err = json.Unmarshal([]byte(body), &s)
-	if err == nil {
-		s.client = c
-	}
-	return s, err
+	s.client = c
+	return s, nil
 }
 
 // GetServerForToken retreives a server struct for
 // the server token supplied
 func (c Client) GetServerByID(serverID string) (Server, error) {
-	body, err := raw.ResponseFromPostmarkPost(
-		c.HostOrDefault(),
-		fmt.Sprintf(
-			"/servers/%s",
-			serverID,
-		),
-		map[string]string{
-			"X-Postmark-Account-Token": c.AccountToken,
+	return c.GetServerByIDContext(context.Background(), serverID)
+}
+
+// GetServerByIDContext is GetServerByID
+// with a caller-supplied context
+func (c Client) GetServerByIDContext(ctx context.Context, serverID string) (Server, error) {
+	var s Server
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method: http.MethodGet,
+			Path: fmt.Sprintf(
+				"/servers/%s",
+				url.PathEscape(serverID),
+			),
+			Headers: c.accountHeaders(),
 		},
-		nil,
+		&s,
 	)
 	if err != nil {
 		return Server{}, err
 	}
 
-	var s Server
-	err = json.Unmarshal([]byte(body), &s)
-	if err == nil {
-		s.client = c
-	}
-	return s, err
+	s.client = c
+	return s, nil
 }
 
-func (c Client) getServersRecursively(offset, count int, namefilter string) ([]Server, error) {
-	url := fmt.Sprintf(
-		"/servers?count=%d&offset=%d",
-		count,
-		offset,
-	)
+func (c Client) getServersRecursively(ctx context.Context, offset, count int, namefilter string) ([]Server, error) {
+	query := pageValues(count, offset)
 	if namefilter != "" {
-		url = fmt.Sprintf(
-			"%s&name=%s",
-			url,
-			namefilter,
-		)
+		query.Set("name", namefilter)
 	}
-	body, err := raw.ResponseFromPostmarkPost(
-		c.HostOrDefault(),
-		url,
-		map[string]string{
-			"X-Postmark-Account-Token": c.AccountToken,
+
+	var serversResponse servers
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodGet,
+			Path:    "/servers",
+			Headers: c.accountHeaders(),
+			Query:   query,
 		},
-		nil,
+		&serversResponse,
 	)
 	if err != nil {
 		return []Server{}, err
 	}
 
-	var serversResponse servers
-	err = json.Unmarshal([]byte(body), &serversResponse)
-	if err != nil {
-		return serversResponse.Servers, err
-	}
-
 	// Associate the account token
 	returnServers := make([]Server, 0, serversResponse.TotalCount)
 	for _, server := range serversResponse.Servers {
@@ -148,6 +212,7 @@ func (c Client) getServersRecursively(offset, count int, namefilter string) ([]S
 
 	if serversResponse.TotalCount > offset+count {
 		moreServers, err := c.getServersRecursively(
+			ctx,
 			offset+count,
 			count,
 			namefilter,
@@ -163,60 +228,68 @@ func (c Client) getServersRecursively(offset, count int, namefilter string) ([]S
 }
 
 func (c Client) GetAllServers(namefilter string) ([]Server, error) {
-	return c.getServersRecursively(0, 25, namefilter)
+	return c.GetAllServersContext(context.Background(), namefilter)
 }
 
-// SendMessage sends a single message through Postmark
+// GetAllServersContext is GetAllServers
+// with a caller-supplied context
+func (c Client) GetAllServersContext(ctx context.Context, namefilter string) ([]Server, error) {
+	return c.getServersRecursively(ctx, 0, 25, namefilter)
+}
+
+// SendMessage sends a single message through Postmark.
+// Attachments are base64 encoded straight from their
+// Readers into the request, never held whole in memory.
 func (c Client) SendMessage(message *Message) (MessageSendResponse, error) {
+	return c.SendMessageContext(context.Background(), message)
+}
+
+// SendMessageContext is SendMessage
+// with a caller-supplied context
+func (c Client) SendMessageContext(ctx context.Context, message *Message) (MessageSendResponse, error) {
 	if c.ServerToken == "" {
 		return MessageSendResponse{}, errors.New("ServerToken must be set in Client")
 	}
-	if message.TrackOpens && message.HtmlBody == "" && message.TemplateId == 0 {
-		fmt.Println("WARNING: TrackOpens is a NOOP on messages without an HtmlBody set.")
-	}
-
-	// Get the internal JSON
-	bytes, err := json.Marshal(message)
+	// prepare enforces the 10MB limit where the size can be
+	// worked out up front; Readers of unknown length are
+	// checked as the body is written instead
+	enc, err := message.prepare()
 	if err != nil {
 		return MessageSendResponse{}, err
 	}
 
-	// Check the size against the 10MB limit
-	size := len(bytes)
-	if size > 1024*1000*10 {
-		return MessageSendResponse{},
-			fmt.Errorf(
-				"message + attachments cannot excede 10MB. Current size: %d Bytes",
-				size,
-			)
-	}
-
 	// Post and get the response
 	url := "/email"
-	if message.TemplateId != 0 {
+	if message.usesTemplate() {
 		url = "/email/withTemplate"
 	}
-	body, err := raw.ResponseFromPostmarkPost(
-		c.HostOrDefault(),
-		url,
-		map[string]string{
-			"X-Postmark-Server-Token": c.ServerToken,
+	var msr MessageSendResponse
+	err = c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodPost,
+			Path:    url,
+			Headers: c.serverHeaders(),
+			Body:    enc.body(),
 		},
-		string(bytes),
+		&msr,
 	)
-
 	if err != nil {
 		return MessageSendResponse{}, err
 	}
 
-	// Unmarshal response
-	var msr MessageSendResponse
-	err = json.Unmarshal([]byte(body), &msr)
-	return msr, err
+	return msr, nil
 }
 
-// SendMessages batch-sends Messages
+// SendMessages batch-sends Messages. Attachments are
+// streamed into the request as SendMessage does.
 func (c Client) SendMessages(messages []*Message) ([]MessageSendResponse, error) {
+	return c.SendMessagesContext(context.Background(), messages)
+}
+
+// SendMessagesContext is SendMessages
+// with a caller-supplied context
+func (c Client) SendMessagesContext(ctx context.Context, messages []*Message) ([]MessageSendResponse, error) {
 	if len(messages) > 500 {
 		return []MessageSendResponse{}, errors.New("cannot send over 500 messages in a single batch")
 	}
@@ -224,37 +297,49 @@ func (c Client) SendMessages(messages []*Message) ([]MessageSendResponse, error)
 		return []MessageSendResponse{}, errors.New("ServerToken must be set in Client")
 	}
 
-	// Postmark doesn not yet support sending batch
-	// emails with template attachments
+	// Templated messages go through their own batch
+	// endpoint, so a batch cannot mix the two
+	templated := 0
 	for _, message := range messages {
-		if message.TemplateId != 0 {
-			return []MessageSendResponse{}, errors.New("batch sending with templates not supported")
+		if message.usesTemplate() {
+			templated++
 		}
 	}
+	if templated != 0 && templated != len(messages) {
+		return []MessageSendResponse{}, errors.New("cannot mix templated and non-templated messages in a single batch")
+	}
 
-	// Get the internal JSON. The
-	// array should marshal properly
-	bytes, err := json.Marshal(messages)
-	if err != nil {
-		return []MessageSendResponse{}, err
+	// Each message is validated and measured as SendMessage
+	// does, and the batch is streamed like a single message
+	batch := &batchEncoder{templated: templated != 0}
+	for i, message := range messages {
+		enc, err := message.prepare()
+		if err != nil {
+			return []MessageSendResponse{}, fmt.Errorf("message %d: %w", i, err)
+		}
+		batch.messages = append(batch.messages, enc)
+	}
+	if size, ok := batch.size(); ok && size > maxBatchSize {
+		return []MessageSendResponse{}, fmt.Errorf("batch cannot excede 50MB. Current size: %d Bytes", size)
 	}
 
-	// Post and get the response
-	body, err := raw.ResponseFromPostmarkPost(
-		c.HostOrDefault(),
-		"/email/batch",
-		map[string]string{
-			"X-Postmark-Server-Token": c.ServerToken,
-		},
-		string(bytes),
-	)
-	if err != nil {
-		return []MessageSendResponse{}, err
+	path := "/email/batch"
+	if batch.templated {
+		path = "/email/batchWithTemplates"
 	}
 
-	// Unmarshal the response
+	// Post and get the response
 	var responses []MessageSendResponse
-	err = json.Unmarshal([]byte(body), &responses)
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodPost,
+			Path:    path,
+			Headers: c.serverHeaders(),
+			Body:    batch.body(),
+		},
+		&responses,
+	)
 	if err != nil {
 		return []MessageSendResponse{}, err
 	}
@@ -262,30 +347,82 @@ func (c Client) SendMessages(messages []*Message) ([]MessageSendResponse, error)
 	return responses, nil
 }
 
+// SearchMessages searches outbound or inbound messages.
+// Inbound results are flattened into SearchResult; use
+// SearchInboundMessages for the full inbound fields.
 func (c Client) SearchMessages(outbound bool, packet MessageSearchPacket) (SearchResults, error) {
+	return c.SearchMessagesContext(context.Background(), outbound, packet)
+}
+
+// SearchMessagesContext is SearchMessages
+// with a caller-supplied context
+func (c Client) SearchMessagesContext(ctx context.Context, outbound bool, packet MessageSearchPacket) (SearchResults, error) {
 	switch outbound {
 	case true:
-		return c.searchOutboudMessages(packet)
+		return c.searchOutboudMessages(ctx, packet)
 	default:
-		return SearchResults{}, errors.New("not yet implemented")
+		return c.searchInboundAsSearchResults(ctx, packet)
 	}
 }
 
-func (c Client) searchOutboudMessages(packet MessageSearchPacket) (SearchResults, error) {
-	urlValues := packet.AsValues()
-	respText, err := raw.ResponseFromPostmarkGet(
-		c.Host,
-		"/messages/outbound",
-		map[string]string{
-			"X-Postmark-Server-Token": c.ServerToken,
+func (c Client) searchOutboudMessages(ctx context.Context, packet MessageSearchPacket) (SearchResults, error) {
+	var sr SearchResults
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodGet,
+			Path:    "/messages/outbound",
+			Headers: c.serverHeaders(),
+			Query:   packet.AsValues(),
 		},
-		urlValues,
+		&sr,
 	)
 	if err != nil {
 		return SearchResults{}, err
 	}
 
-	var sr SearchResults
-	err = json.Unmarshal([]byte(respText), &sr)
-	return sr, err
+	return sr, nil
+}
+
+// SearchInboundMessages searches messages received by the server
+func (c Client) SearchInboundMessages(packet InboundMessageSearchPacket) (InboundSearchResults, error) {
+	return c.SearchInboundMessagesContext(context.Background(), packet)
+}
+
+// SearchInboundMessagesContext is SearchInboundMessages
+// with a caller-supplied context
+func (c Client) SearchInboundMessagesContext(ctx context.Context, packet InboundMessageSearchPacket) (InboundSearchResults, error) {
+	var isr InboundSearchResults
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodGet,
+			Path:    "/messages/inbound",
+			Headers: c.serverHeaders(),
+			Query:   packet.AsValues(),
+		},
+		&isr,
+	)
+	if err != nil {
+		return InboundSearchResults{}, err
+	}
+
+	return isr, nil
+}
+
+func (c Client) searchInboundAsSearchResults(ctx context.Context, packet MessageSearchPacket) (SearchResults, error) {
+	isr, err := c.SearchInboundMessagesContext(ctx, packet.inboundPacket())
+	if err != nil {
+		return SearchResults{}, err
+	}
+
+	sr := SearchResults{
+		TotalCount: isr.TotalCount,
+		Messages:   make([]SearchResult, 0, len(isr.InboundMessages)),
+	}
+	for _, message := range isr.InboundMessages {
+		sr.Messages = append(sr.Messages, message.searchResult())
+	}
+
+	return sr, nil
 }
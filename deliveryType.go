@@ -0,0 +1,10 @@
+package gostmark
+
+// DeliveryType determines whether a server delivers
+// mail or only accepts it for testing
+type DeliveryType string
+
+const (
+	DeliveryTypeLive    DeliveryType = "Live"
+	DeliveryTypeSandbox DeliveryType = "Sandbox"
+)
@@ -0,0 +1,89 @@
+package gostmark
+
+// DNSSettings are the SPF, DKIM and Return-Path details
+// shared by sender signatures and domains
+type DNSSettings struct {
+	SPFVerified  bool
+	SPFHost      string
+	SPFTextValue string
+
+	DKIMVerified                  bool
+	WeakDKIM                      bool
+	DKIMHost                      string
+	DKIMTextValue                 string
+	DKIMPendingHost               string
+	DKIMPendingTextValue          string
+	DKIMRevokedHost               string
+	DKIMRevokedTextValue          string
+	SafeToRemoveRevokedKeyFromDNS bool
+	DKIMUpdateStatus              string
+
+	ReturnPathDomain           string
+	ReturnPathDomainVerified   bool
+	ReturnPathDomainCNAMEValue string
+}
+
+// DNSRecord is a record to be published for a domain
+type DNSRecord struct {
+	Type  string
+	Host  string
+	Value string
+}
+
+// DKIMRecord returns the active DKIM key's TXT record
+func (d DNSSettings) DKIMRecord() DNSRecord {
+	return DNSRecord{
+		Type:  "TXT",
+		Host:  d.DKIMHost,
+		Value: d.DKIMTextValue,
+	}
+}
+
+// PendingDKIMRecord returns the TXT record of a DKIM
+// key that is awaiting verification, if any
+func (d DNSSettings) PendingDKIMRecord() DNSRecord {
+	return DNSRecord{
+		Type:  "TXT",
+		Host:  d.DKIMPendingHost,
+		Value: d.DKIMPendingTextValue,
+	}
+}
+
+// SPFRecord returns the SPF TXT record
+func (d DNSSettings) SPFRecord() DNSRecord {
+	return DNSRecord{
+		Type:  "TXT",
+		Host:  d.SPFHost,
+		Value: d.SPFTextValue,
+	}
+}
+
+// ReturnPathRecord returns the CNAME record
+// for the custom Return-Path domain
+func (d DNSSettings) ReturnPathRecord() DNSRecord {
+	return DNSRecord{
+		Type:  "CNAME",
+		Host:  d.ReturnPathDomain,
+		Value: d.ReturnPathDomainCNAMEValue,
+	}
+}
+
+// Records returns every record that should currently
+// be published, skipping those Postmark left blank
+func (d DNSSettings) Records() []DNSRecord {
+	candidates := []DNSRecord{
+		d.DKIMRecord(),
+		d.PendingDKIMRecord(),
+		d.SPFRecord(),
+		d.ReturnPathRecord(),
+	}
+
+	records := make([]DNSRecord, 0, len(candidates))
+	for _, record := range candidates {
+		if record.Host != "" && record.Value != "" {
+			records = append(records, record)
+		}
+	}
+
+	return records
+}
@@ -0,0 +1,186 @@
+package gostmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/themartorana/Gostmark/v2/raw"
+)
+
+// Domain is a domain as returned by ListDomains
+type Domain struct {
+	ID                       int
+	Name                     string
+	SPFVerified              bool
+	DKIMVerified             bool
+	WeakDKIM                 bool
+	ReturnPathDomainVerified bool
+}
+
+type DomainDetails struct {
+	ID   int
+	Name string
+
+	DNSSettings
+}
+
+type DomainList struct {
+	TotalCount int
+	Domains    []Domain
+}
+
+// DomainPacket creates or edits a domain.
+// Name is only used on creation.
+type DomainPacket struct {
+	Name             string `json:",omitempty"`
+	ReturnPathDomain string `json:",omitempty"`
+}
+
+func domainPath(domainID int, action string) string {
+	return fmt.Sprintf(
+		"/domains/%d%s",
+		domainID,
+		action,
+	)
+}
+
+// ListDomains lists the domains on the account
+func (c Client) ListDomains(count, offset int) (DomainList, error) {
+	return c.ListDomainsContext(context.Background(), count, offset)
+}
+
+// ListDomainsContext is ListDomains
+// with a caller-supplied context
+func (c Client) ListDomainsContext(ctx context.Context, count, offset int) (DomainList, error) {
+	var dl DomainList
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodGet,
+			Path:    "/domains",
+			Headers: c.accountHeaders(),
+			Query:   pageValues(count, offset),
+		},
+		&dl,
+	)
+	if err != nil {
+		return DomainList{}, err
+	}
+
+	return dl, nil
+}
+
+// GetDomain retrieves a domain along with its DNS settings
+func (c Client) GetDomain(domainID int) (DomainDetails, error) {
+	return c.GetDomainContext(context.Background(), domainID)
+}
+
+// GetDomainContext is GetDomain
+// with a caller-supplied context
+func (c Client) GetDomainContext(ctx context.Context, domainID int) (DomainDetails, error) {
+	return c.domainRequest(ctx, http.MethodGet, domainPath(domainID, ""), nil)
+}
+
+// CreateDomain adds a domain to the account
+func (c Client) CreateDomain(packet DomainPacket) (DomainDetails, error) {
+	return c.CreateDomainContext(context.Background(), packet)
+}
+
+// CreateDomainContext is CreateDomain
+// with a caller-supplied context
+func (c Client) CreateDomainContext(ctx context.Context, packet DomainPacket) (DomainDetails, error) {
+	if packet.Name == "" {
+		return DomainDetails{}, errors.New("Domain name required")
+	}
+
+	return c.domainRequest(ctx, http.MethodPost, "/domains", packet)
+}
+
+// EditDomain updates a domain's Return-Path domain
+func (c Client) EditDomain(domainID int, packet DomainPacket) (DomainDetails, error) {
+	return c.EditDomainContext(context.Background(), domainID, packet)
+}
+
+// EditDomainContext is EditDomain
+// with a caller-supplied context
+func (c Client) EditDomainContext(ctx context.Context, domainID int, packet DomainPacket) (DomainDetails, error) {
+	// The domain name cannot be changed
+	packet.Name = ""
+	return c.domainRequest(ctx, http.MethodPut, domainPath(domainID, ""), packet)
+}
+
+// DeleteDomain removes a domain from the account
+func (c Client) DeleteDomain(domainID int) error {
+	return c.DeleteDomainContext(context.Background(), domainID)
+}
+
+// DeleteDomainContext is DeleteDomain
+// with a caller-supplied context
+func (c Client) DeleteDomainContext(ctx context.Context, domainID int) error {
+	return c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodDelete,
+			Path:    domainPath(domainID, ""),
+			Headers: c.accountHeaders(),
+		},
+		nil,
+	)
+}
+
+// VerifyDomainDKIM asks Postmark to check the
+// domain's DKIM record and returns the result
+func (c Client) VerifyDomainDKIM(domainID int) (DomainDetails, error) {
+	return c.VerifyDomainDKIMContext(context.Background(), domainID)
+}
+
+// VerifyDomainDKIMContext is VerifyDomainDKIM
+// with a caller-supplied context
+func (c Client) VerifyDomainDKIMContext(ctx context.Context, domainID int) (DomainDetails, error) {
+	return c.domainRequest(ctx, http.MethodPut, domainPath(domainID, "/verifyDkim"), nil)
+}
+
+// VerifyDomainReturnPath asks Postmark to check the
+// domain's Return-Path CNAME and returns the result
+func (c Client) VerifyDomainReturnPath(domainID int) (DomainDetails, error) {
+	return c.VerifyDomainReturnPathContext(context.Background(), domainID)
+}
+
+// VerifyDomainReturnPathContext is VerifyDomainReturnPath
+// with a caller-supplied context
+func (c Client) VerifyDomainReturnPathContext(ctx context.Context, domainID int) (DomainDetails, error) {
+	return c.domainRequest(ctx, http.MethodPut, domainPath(domainID, "/verifyReturnPath"), nil)
+}
+
+// RotateDomainDKIM creates a new DKIM key for the domain.
+// The new key is returned as the pending DKIM record.
+func (c Client) RotateDomainDKIM(domainID int) (DomainDetails, error) {
+	return c.RotateDomainDKIMContext(context.Background(), domainID)
+}
+
+// RotateDomainDKIMContext is RotateDomainDKIM
+// with a caller-supplied context
+func (c Client) RotateDomainDKIMContext(ctx context.Context, domainID int) (DomainDetails, error) {
+	return c.domainRequest(ctx, http.MethodPost, domainPath(domainID, "/rotatedkim"), nil)
+}
+
+func (c Client) domainRequest(ctx context.Context, method, path string, body interface{}) (DomainDetails, error) {
+	var dd DomainDetails
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  method,
+			Path:    path,
+			Headers: c.accountHeaders(),
+			Body:    body,
+		},
+		&dd,
+	)
+	if err != nil {
+		return DomainDetails{}, err
+	}
+
+	return dd, nil
+}
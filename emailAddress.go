@@ -1,6 +1,8 @@
 package gostmark
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/mail"
@@ -42,9 +44,106 @@ func (e EmailAddress) MarshalJSON() ([]byte, error) {
 	if err != nil {
 		return []byte(""), err
 	}
-	s = strings.Replace(s, "\"", "\\\"", -1)
-	s = fmt.Sprintf("\"%s\"", s)
-	return []byte(s), nil
+
+	return json.Marshal(s)
+}
+
+// UnmarshalJSON accepts the address either as a string,
+// "Name <email>" or a bare email, or as an object with
+// Email and Name fields as the API returns it
+func (e *EmailAddress) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+
+	if len(b) > 0 && b[0] == '{' {
+		// A distinct type so this method
+		// is not called recursively
+		var object struct {
+			Name  string
+			Email string
+		}
+		if err := json.Unmarshal(b, &object); err != nil {
+			return err
+		}
+
+		*e = EmailAddress(object)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if strings.TrimSpace(s) == "" {
+		*e = EmailAddress{}
+		return nil
+	}
+
+	address, err := mail.ParseAddress(s)
+	if err != nil {
+		return fmt.Errorf("invalid email address %q: %w", s, err)
+	}
+
+	*e = EmailAddress{
+		Name:  address.Name,
+		Email: address.Address,
+	}
+	return nil
+}
+
+// addressList reads a list of addresses either as a
+// comma separated string, as messages are sent, or
+// as an array of addresses
+type addressList []EmailAddress
+
+func (al *addressList) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '[' {
+		var addresses []EmailAddress
+		if err := json.Unmarshal(b, &addresses); err != nil {
+			return err
+		}
+
+		*al = addresses
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	addresses, err := ParseAddressList(s)
+	if err != nil {
+		return err
+	}
+
+	*al = addresses
+	return nil
+}
+
+// ParseAddressList parses a comma separated list of
+// RFC 5322 addresses, such as "Ann <ann@example.com>,
+// bob@example.com". A blank list parses to no addresses.
+func ParseAddressList(list string) ([]EmailAddress, error) {
+	if strings.TrimSpace(list) == "" {
+		return []EmailAddress{}, nil
+	}
+
+	parsed, err := mail.ParseAddressList(list)
+	if err != nil {
+		return []EmailAddress{}, err
+	}
+
+	addresses := make([]EmailAddress, 0, len(parsed))
+	for _, address := range parsed {
+		addresses = append(addresses, EmailAddress{
+			Name:  address.Name,
+			Email: address.Address,
+		})
+	}
+
+	return addresses, nil
 }
 
 // joinEmailAddresses is a convenience function to return a
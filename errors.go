@@ -0,0 +1,130 @@
+package gostmark
+
+import (
+	"errors"
+
+	"github.com/themartorana/Gostmark/v2/raw"
+)
+
+// APIError is returned by every Client method when Postmark
+// answers with anything other than a 200. Use errors.As to
+// inspect the HTTP status and Postmark error code.
+type APIError = raw.APIError
+
+// Postmark API error codes, as documented at
+// https://postmarkapp.com/developer/api/overview#error-codes
+const (
+	ErrorCodeBadOrMissingAPIToken         = 10
+	ErrorCodeMaintenance                  = 100
+	ErrorCodeInvalidEmailRequest          = 300
+	ErrorCodeSenderSignatureNotFound      = 400
+	ErrorCodeSenderSignatureNotConfirmed  = 401
+	ErrorCodeInvalidJSON                  = 402
+	ErrorCodeIncompatibleJSON             = 403
+	ErrorCodeNotAllowedToSend             = 405
+	ErrorCodeInactiveRecipient            = 406
+	ErrorCodeBounceNotFound               = 407
+	ErrorCodeBounceQueryException         = 408
+	ErrorCodeJSONRequired                 = 409
+	ErrorCodeTooManyBatchMessages         = 410
+	ErrorCodeForbiddenAttachmentType      = 411
+	ErrorCodeAccountIsPending             = 412
+	ErrorCodeAccountMayNotSend            = 413
+	ErrorCodeSenderSignatureQuery         = 500
+	ErrorCodeSenderSignatureNotFoundByID  = 501
+	ErrorCodeNoUpdatedSenderSignatureData = 502
+	ErrorCodePublicDomainNotAllowed       = 503
+	ErrorCodeSenderSignatureExists        = 504
+	ErrorCodeDKIMAlreadyScheduled         = 505
+	ErrorCodeSenderSignatureConfirmed     = 506
+	ErrorCodeSenderSignatureNotOwned      = 507
+	ErrorCodeDomainNotFound               = 510
+	ErrorCodeInvalidFields                = 511
+	ErrorCodeDomainExists                 = 512
+	ErrorCodeDomainNotOwned               = 513
+	ErrorCodeServerQueryException         = 600
+	ErrorCodeServerNotFound               = 601
+	ErrorCodeDuplicateInboundDomain       = 602
+	ErrorCodeServerNameExists             = 603
+	ErrorCodeNoDeleteAccess               = 604
+	ErrorCodeUnableToDeleteServer         = 605
+	ErrorCodeInvalidWebhookURL            = 606
+	ErrorCodeInvalidServerColor           = 607
+	ErrorCodeServerNameMissing            = 608
+	ErrorCodeNoUpdatedServerData          = 609
+	ErrorCodeInvalidInboundMXRecord       = 610
+	ErrorCodeInvalidSpamThreshold         = 611
+	ErrorCodeMessagesQueryException       = 700
+	ErrorCodeMessageNotFound              = 701
+	ErrorCodeCannotBypassBlockedInbound   = 702
+	ErrorCodeCannotRetryFailedInbound     = 703
+	ErrorCodeTemplateQueryException       = 1100
+	ErrorCodeTemplateNotFound             = 1101
+	ErrorCodeTemplateLimitExceeded        = 1105
+	ErrorCodeNoTemplateData               = 1109
+	ErrorCodeTemplateFieldMissing         = 1120
+	ErrorCodeTemplateFieldTooLarge        = 1121
+	ErrorCodeInvalidTemplateField         = 1122
+	ErrorCodeTemplateFieldNotAllowed      = 1123
+)
+
+// AsAPIError returns the APIError wrapped by err, if any
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+
+	return nil, false
+}
+
+// ErrorCodeOf returns the Postmark error code carried
+// by err, or 0 if err is not an APIError
+func ErrorCodeOf(err error) int {
+	if apiErr, ok := AsAPIError(err); ok {
+		return apiErr.ErrorCode
+	}
+
+	return 0
+}
+
+// IsInactiveRecipient reports whether the send failed because
+// the recipient has been deactivated by a hard bounce, spam
+// complaint or manual suppression
+func IsInactiveRecipient(err error) bool {
+	return ErrorCodeOf(err) == ErrorCodeInactiveRecipient
+}
+
+// IsInvalidEmailRequest reports whether Postmark rejected
+// the message itself, e.g. for a malformed address
+func IsInvalidEmailRequest(err error) bool {
+	return ErrorCodeOf(err) == ErrorCodeInvalidEmailRequest
+}
+
+// IsUnauthorized reports whether the API token
+// was missing or rejected
+func IsUnauthorized(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	if !ok {
+		return false
+	}
+
+	return apiErr.StatusCode == 401 || apiErr.ErrorCode == ErrorCodeBadOrMissingAPIToken
+}
+
+// IsNotFound reports whether the requested server, message,
+// bounce, template or other entity does not exist
+func IsNotFound(err error) bool {
+	switch ErrorCodeOf(err) {
+	case ErrorCodeSenderSignatureNotFound,
+		ErrorCodeBounceNotFound,
+		ErrorCodeSenderSignatureNotFoundByID,
+		ErrorCodeDomainNotFound,
+		ErrorCodeServerNotFound,
+		ErrorCodeMessageNotFound,
+		ErrorCodeTemplateNotFound:
+		return true
+	default:
+		return false
+	}
+}
@@ -1,11 +1,3 @@
 module github.com/themartorana/Gostmark/v2
 
 go 1.17
-
-require github.com/franela/goreq v0.0.0-20171204163338-bcd34c9993f8
-
-require (
-	github.com/franela/goblin v0.0.0-20211003143422-0a4f594942bf // indirect
-	github.com/onsi/gomega v1.18.0 // indirect
-	golang.org/x/net v0.0.0-20220121210141-e204ce36a2ba // indirect
-)
@@ -0,0 +1,135 @@
+package gostmark
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"html"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// imgSrc matches the src attribute of an <img> tag, quoted
+// with either kind of quote or unquoted
+var imgSrc = regexp.MustCompile(`(?is)<img\b[^>]*?\ssrc\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+// EmbedImages attaches the images the HtmlBody refers to by a
+// local path, reading them from fsys, and points each <img src>
+// at its attachment with a cid: reference so the images show
+// in clients that block remote content. Remote, data: and cid:
+// sources are left alone, and an image used more than once is
+// attached once. Paths are taken relative to the root of fsys.
+//
+// On error nothing is changed. Close the message's attachments
+// once it has been sent.
+func (m *Message) EmbedImages(fsys fs.FS) error {
+	matches := imgSrc.FindAllStringSubmatchIndex(m.HtmlBody, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	var (
+		body        strings.Builder
+		last        int
+		attachments []*Attachment
+		contentIDs  = make(map[string]string)
+	)
+	for _, match := range matches {
+		// Whichever of the three src forms matched
+		start, end := -1, -1
+		for group := 1; group <= 3; group++ {
+			if match[2*group] >= 0 {
+				start, end = match[2*group], match[2*group+1]
+				break
+			}
+		}
+
+		filePath, ok := localImagePath(m.HtmlBody[start:end])
+		if !ok {
+			continue
+		}
+
+		contentID, ok := contentIDs[filePath]
+		if !ok {
+			attachment, err := AttachmentFromFS(fsys, filePath)
+			if err != nil {
+				closeAttachments(attachments)
+				return err
+			}
+
+			id, err := newContentID(attachment.Name)
+			if err != nil {
+				attachment.Close()
+				closeAttachments(attachments)
+				return err
+			}
+
+			contentID = "cid:" + id
+			attachment.ContentID = contentID
+			attachments = append(attachments, attachment)
+			contentIDs[filePath] = contentID
+		}
+
+		body.WriteString(m.HtmlBody[last:start])
+		body.WriteString(contentID)
+		last = end
+	}
+	if len(attachments) == 0 {
+		return nil
+	}
+	body.WriteString(m.HtmlBody[last:])
+
+	m.Mutex.Lock()
+	m.HtmlBody = body.String()
+	m.Attachments = append(m.Attachments, attachments...)
+	m.Mutex.Unlock()
+
+	return nil
+}
+
+// EmbedLocalImages is EmbedImages reading
+// images from the directory dir
+func (m *Message) EmbedLocalImages(dir string) error {
+	return m.EmbedImages(os.DirFS(dir))
+}
+
+// localImagePath returns the fs.FS path an <img src>
+// refers to, and false if it is not a local file
+func localImagePath(src string) (string, bool) {
+	src = strings.TrimSpace(html.UnescapeString(src))
+	if src == "" || strings.HasPrefix(src, "//") {
+		return "", false
+	}
+
+	u, err := url.Parse(src)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return "", false
+	}
+
+	filePath := path.Clean(strings.TrimPrefix(u.Path, "/"))
+	if !fs.ValidPath(filePath) || filePath == "." {
+		return "", false
+	}
+
+	return filePath, true
+}
+
+// newContentID returns a random Content-ID
+// for an inline attachment named name
+func newContentID(name string) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b) + "." + url.PathEscape(name), nil
+}
+
+func closeAttachments(attachments []*Attachment) {
+	for _, attachment := range attachments {
+		attachment.Close()
+	}
+}
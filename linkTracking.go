@@ -0,0 +1,22 @@
+package gostmark
+
+// LinkTracking controls which message bodies
+// Postmark rewrites links in for click tracking
+type LinkTracking string
+
+const (
+	TrackLinksNone        LinkTracking = "None"
+	TrackLinksHtmlAndText LinkTracking = "HtmlAndText"
+	TrackLinksHtmlOnly    LinkTracking = "HtmlOnly"
+	TrackLinksTextOnly    LinkTracking = "TextOnly"
+)
+
+// Valid reports whether lt is a value Postmark accepts
+func (lt LinkTracking) Valid() bool {
+	switch lt {
+	case TrackLinksNone, TrackLinksHtmlAndText, TrackLinksHtmlOnly, TrackLinksTextOnly:
+		return true
+	default:
+		return false
+	}
+}
@@ -2,17 +2,22 @@ package gostmark
 
 import (
 	"encoding/json"
-	"errors"
-
 	"sync"
 	"time"
 )
 
+// maxRecipients is the most addresses a
+// message can be sent to across To, Cc and Bcc
+const maxRecipients = 50
+
 type Message struct {
 	From    EmailAddress
 	ReplyTo EmailAddress
-	To      EmailAddress
 
+	// To, Cc and Bcc may hold up to 50 recipients
+	// between them. To was a single EmailAddress in
+	// earlier releases; see SetTo for migrating.
+	To  []EmailAddress
 	Cc  []EmailAddress
 	Bcc []EmailAddress
 
@@ -25,10 +30,26 @@ type Message struct {
 	Tag        string
 	TrackOpens bool
 
+	// TrackLinks enables click tracking. Empty
+	// uses the server's setting.
+	TrackLinks LinkTracking
+
+	// Metadata is stored with the message and returned
+	// in searches and webhooks. Up to 10 fields, with
+	// keys of up to 20 characters and values up to 80.
+	Metadata map[string]string
+
+	// MessageStream is the ID of the stream to send
+	// through. Empty uses the server's default
+	// transactional stream.
+	MessageStream string
+
 	Attachments []*Attachment
 
-	// Template stuff, to incorporate eventually
+	// Template stuff. TemplateAlias is used
+	// when TemplateId is not set
 	TemplateId    int
+	TemplateAlias string
 	TemplateModel interface{}
 	InlineCSS     bool
 
@@ -44,18 +65,58 @@ type MessageSendResponse struct {
 	Message   string
 }
 
+// Err returns the per-message failure reported in a
+// batch response as an *APIError, or nil on success
+func (r MessageSendResponse) Err() error {
+	if r.ErrorCode == 0 {
+		return nil
+	}
+
+	return &APIError{
+		StatusCode: 200,
+		ErrorCode:  r.ErrorCode,
+		Message:    r.Message,
+	}
+}
+
 func (m *Message) AddAttachment(attachment *Attachment) {
 	m.Mutex.Lock()
 	m.Attachments = append(m.Attachments, attachment)
 	m.Mutex.Unlock()
 }
 
+// SetMetadata adds a metadata field, replacing
+// any existing value for key
+func (m *Message) SetMetadata(key, value string) {
+	m.Mutex.Lock()
+	if m.Metadata == nil {
+		m.Metadata = make(map[string]string)
+	}
+	m.Metadata[key] = value
+	m.Mutex.Unlock()
+}
+
 func (m *Message) AddHeader(header Header) {
 	m.Mutex.Lock()
 	m.Headers = append(m.Headers, header)
 	m.Mutex.Unlock()
 }
 
+// SetTo replaces the message's To recipients. To used to hold
+// a single EmailAddress; code assigning one, m.To = address,
+// migrates to m.SetTo(address).
+func (m *Message) SetTo(addresses ...EmailAddress) {
+	m.Mutex.Lock()
+	m.To = append([]EmailAddress(nil), addresses...)
+	m.Mutex.Unlock()
+}
+
+func (m *Message) AddTo(name, emailAddress string) {
+	ea := EmailAddressForEmail(emailAddress)
+	ea.Name = name
+	m.AddEmailToTo(ea)
+}
+
 func (m *Message) AddCc(name, emailAddress string) {
 	ea := EmailAddressForEmail(emailAddress)
 	ea.Name = name
@@ -68,6 +129,12 @@ func (m *Message) AddBcc(name, emailAddress string) {
 	m.AddEmailToBcc(ea)
 }
 
+func (m *Message) AddEmailToTo(email EmailAddress) {
+	m.Mutex.Lock()
+	m.To = append(m.To, email)
+	m.Mutex.Unlock()
+}
+
 func (m *Message) AddEmailToCc(email EmailAddress) {
 	m.Mutex.Lock()
 	m.Cc = append(m.Cc, email)
@@ -81,36 +148,94 @@ func (m *Message) AddEmailToBcc(email EmailAddress) {
 }
 
 func (m *Message) MarshalJSON() ([]byte, error) {
-	if m.To.Email == "" {
-		return []byte{}, errors.New("to EmailAddress required")
-	}
-	if m.From.Email == "" {
-		return []byte{}, errors.New("from EmailAddress required")
+	enc, err := m.prepare()
+	if err != nil {
+		return []byte{}, err
 	}
-	if len(m.Cc) > 50 {
-		return []byte{}, errors.New("cc field cannot contain more than 50 entries")
+
+	return enc.bytes()
+}
+
+// UnmarshalJSON reads a message in the form MarshalJSON
+// writes it, so that messages can be stored as JSON and
+// reloaded to be sent later. TemplateModel is kept as a
+// json.RawMessage so that it is written back unchanged.
+func (m *Message) UnmarshalJSON(b []byte) error {
+	var packet struct {
+		From          EmailAddress
+		ReplyTo       EmailAddress
+		To            addressList
+		Cc            addressList
+		Bcc           addressList
+		Subject       string
+		Tag           string
+		HtmlBody      string
+		TextBody      string
+		Headers       []Header
+		TrackOpens    bool
+		TrackLinks    LinkTracking
+		Metadata      map[string]string
+		MessageStream string
+		Attachments   []*Attachment
+		TemplateID    int
+		TemplateAlias string
+		TemplateModel json.RawMessage
+		InlineCss     bool
 	}
-	if len(m.Bcc) > 50 {
-		return []byte{}, errors.New("bcc field cannot contain more than 50 entries")
+	if err := json.Unmarshal(b, &packet); err != nil {
+		return err
 	}
-	if m.HtmlBody == "" && m.TextBody == "" && m.TemplateId == 0 {
-		return []byte{}, errors.New("HtmlBody and TextBody cannot both be blank")
+
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+
+	m.From = packet.From
+	m.ReplyTo = packet.ReplyTo
+	m.To = packet.To
+	m.Cc = packet.Cc
+	m.Bcc = packet.Bcc
+	m.Subject = packet.Subject
+	m.Tag = packet.Tag
+	m.HtmlBody = packet.HtmlBody
+	m.TextBody = packet.TextBody
+	m.Headers = packet.Headers
+	m.TrackOpens = packet.TrackOpens
+	m.TrackLinks = packet.TrackLinks
+	m.Metadata = packet.Metadata
+	m.MessageStream = packet.MessageStream
+	m.Attachments = packet.Attachments
+	m.TemplateId = packet.TemplateID
+	m.TemplateAlias = packet.TemplateAlias
+	m.InlineCSS = packet.InlineCss
+	m.TemplateModel = nil
+	if len(packet.TemplateModel) != 0 && string(packet.TemplateModel) != "null" {
+		m.TemplateModel = packet.TemplateModel
 	}
 
-	// Render
-	packet, err := m.packetToSend()
-	if err != nil {
-		return []byte{}, err
+	return nil
+}
+
+// prepare validates the message and returns the encoder to
+// send it with, or the problems that stop it being sent
+func (m *Message) prepare() (*messageEncoder, error) {
+	problems, enc := m.validate()
+	if err := problems.Err(); err != nil {
+		return nil, err
 	}
 
-	return json.Marshal(packet)
+	return enc, nil
 }
 
 func (m *Message) packetToSend() (map[string]interface{}, error) {
+	to, err := joinEmailAddresses(m.To)
+	if err != nil {
+		return nil, err
+	}
+
 	// Slow, but flexible
 	packet := map[string]interface{}{
 		"From": m.From,
-		"To":   m.To,
+		"To":   to,
 	}
 
 	// Optional fields
@@ -149,15 +274,23 @@ func (m *Message) packetToSend() (map[string]interface{}, error) {
 	if m.TrackOpens {
 		packet["TrackOpens"] = true
 	}
-
-	// Attachments marshal themselves
-	if len(m.Attachments) > 0 {
-		packet["Attachments"] = m.Attachments
+	if m.TrackLinks != "" {
+		packet["TrackLinks"] = m.TrackLinks
+	}
+	if len(m.Metadata) != 0 {
+		packet["Metadata"] = m.Metadata
+	}
+	if m.MessageStream != "" {
+		packet["MessageStream"] = m.MessageStream
 	}
 
 	// Template
-	if m.TemplateId != 0 {
-		packet["TemplateID"] = m.TemplateId
+	if m.usesTemplate() {
+		if m.TemplateId != 0 {
+			packet["TemplateID"] = m.TemplateId
+		} else {
+			packet["TemplateAlias"] = m.TemplateAlias
+		}
 		packet["InlineCss"] = m.InlineCSS
 		if m.TemplateModel != nil {
 			packet["TemplateModel"] = m.TemplateModel
@@ -167,6 +300,12 @@ func (m *Message) packetToSend() (map[string]interface{}, error) {
 	return packet, nil
 }
 
+// usesTemplate reports whether the message is
+// rendered from a template by ID or alias
+func (m *Message) usesTemplate() bool {
+	return m.TemplateId != 0 || m.TemplateAlias != ""
+}
+
 func (m *Message) ccAsString() (string, error) {
 	return joinEmailAddresses(m.Cc)
 }
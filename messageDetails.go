@@ -0,0 +1,122 @@
+package gostmark
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/themartorana/Gostmark/v2/raw"
+)
+
+// AttachmentInfo describes an attachment on a message
+// that has already been sent or received. The content
+// itself is not included.
+type AttachmentInfo struct {
+	Name          string `json:"Name"`
+	ContentType   string `json:"ContentType"`
+	ContentLength int    `json:"ContentLength"`
+	ContentID     string `json:"ContentID"`
+}
+
+// UnmarshalJSON accepts either an attachment object
+// or a bare file name, both of which Postmark returns
+func (ai *AttachmentInfo) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		*ai = AttachmentInfo{Name: name}
+		return nil
+	}
+
+	type attachmentInfo AttachmentInfo
+	return json.Unmarshal(b, (*attachmentInfo)(ai))
+}
+
+// MessageEvent is a single entry in the
+// timeline of an outbound message
+type MessageEvent struct {
+	Recipient  string                 `json:"Recipient"`
+	Type       string                 `json:"Type"`
+	ReceivedAt time.Time              `json:"ReceivedAt"`
+	Details    map[string]interface{} `json:"Details"`
+}
+
+type OutboundMessageDetails struct {
+	SearchResult
+
+	TextBody      string           `json:"TextBody"`
+	HtmlBody      string           `json:"HtmlBody"`
+	Body          string           `json:"Body"`
+	Attachments   []AttachmentInfo `json:"Attachments"`
+	MessageEvents []MessageEvent   `json:"MessageEvents"`
+}
+
+type InboundMessageDetails struct {
+	InboundSearchResult
+
+	TextBody          string   `json:"TextBody"`
+	HtmlBody          string   `json:"HtmlBody"`
+	StrippedTextReply string   `json:"StrippedTextReply"`
+	Headers           []Header `json:"Headers"`
+	BlockedReason     string   `json:"BlockedReason"`
+}
+
+// GetOutboundMessageDetails retrieves the bodies, headers
+// and event history of a message sent through the server
+func (c Client) GetOutboundMessageDetails(messageID string) (OutboundMessageDetails, error) {
+	return c.GetOutboundMessageDetailsContext(context.Background(), messageID)
+}
+
+// GetOutboundMessageDetailsContext is GetOutboundMessageDetails
+// with a caller-supplied context
+func (c Client) GetOutboundMessageDetailsContext(ctx context.Context, messageID string) (OutboundMessageDetails, error) {
+	var details OutboundMessageDetails
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method: http.MethodGet,
+			Path: fmt.Sprintf(
+				"/messages/outbound/%s/details",
+				url.PathEscape(messageID),
+			),
+			Headers: c.serverHeaders(),
+		},
+		&details,
+	)
+	if err != nil {
+		return OutboundMessageDetails{}, err
+	}
+
+	return details, nil
+}
+
+// GetInboundMessageDetails retrieves the bodies, headers
+// and attachment metadata of a message the server received
+func (c Client) GetInboundMessageDetails(messageID string) (InboundMessageDetails, error) {
+	return c.GetInboundMessageDetailsContext(context.Background(), messageID)
+}
+
+// GetInboundMessageDetailsContext is GetInboundMessageDetails
+// with a caller-supplied context
+func (c Client) GetInboundMessageDetailsContext(ctx context.Context, messageID string) (InboundMessageDetails, error) {
+	var details InboundMessageDetails
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method: http.MethodGet,
+			Path: fmt.Sprintf(
+				"/messages/inbound/%s/details",
+				url.PathEscape(messageID),
+			),
+			Headers: c.serverHeaders(),
+		},
+		&details,
+	)
+	if err != nil {
+		return InboundMessageDetails{}, err
+	}
+
+	return details, nil
+}
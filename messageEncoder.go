@@ -0,0 +1,333 @@
+package gostmark
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/themartorana/Gostmark/v2/raw"
+)
+
+const (
+	// maxMessageSize is Postmark's limit on a
+	// message, attachments included, in bytes
+	maxMessageSize = 1024 * 1000 * 10
+
+	// maxBatchSize is Postmark's limit
+	// on a whole batch, in bytes
+	maxBatchSize = 1024 * 1000 * 50
+)
+
+// messageEncoder writes a message as JSON with its
+// attachments base64 encoded in place, so they are
+// never held in memory as a whole. SendMessage and
+// SendMessages stream through it into the request;
+// MarshalJSON writes it to memory.
+type messageEncoder struct {
+	// envelope is every field but Attachments. When there
+	// are attachments its closing brace is left off and
+	// the Attachments array is opened in its place.
+	envelope    []byte
+	attachments []*Attachment
+	headers     [][]byte
+
+	// measure caches size, which seeks the attachments
+	measure sync.Once
+	length  int64
+	known   bool
+}
+
+// encoder prepares m to be written. The
+// message is expected to have been validated.
+func (m *Message) encoder() (*messageEncoder, error) {
+	packet, err := m.packetToSend()
+	if err != nil {
+		return nil, err
+	}
+
+	envelope, err := json.Marshal(packet)
+	if err != nil {
+		return nil, err
+	}
+
+	enc := &messageEncoder{
+		envelope: envelope,
+	}
+	if len(m.Attachments) == 0 {
+		return enc, nil
+	}
+
+	enc.envelope = append(envelope[:len(envelope)-1], `,"Attachments":[`...)
+	enc.attachments = m.Attachments
+	enc.headers = make([][]byte, len(m.Attachments))
+	for i, attachment := range m.Attachments {
+		if attachment == nil {
+			return nil, errors.New("attachment cannot be nil")
+		}
+
+		header, err := attachment.jsonHeader()
+		if err != nil {
+			return nil, err
+		}
+		enc.headers[i] = header
+	}
+
+	return enc, nil
+}
+
+// size returns the exact length of the encoded message,
+// and false if an attachment's length cannot be known
+// without reading it
+func (enc *messageEncoder) size() (int64, bool) {
+	enc.measure.Do(func() {
+		enc.length, enc.known = enc.computeSize()
+	})
+
+	return enc.length, enc.known
+}
+
+func (enc *messageEncoder) computeSize() (int64, bool) {
+	size := int64(len(enc.envelope))
+	if len(enc.attachments) == 0 {
+		return size, true
+	}
+
+	for i, attachment := range enc.attachments {
+		encoded, ok := attachment.encodedSize()
+		if !ok {
+			return 0, false
+		}
+		if i > 0 {
+			size++
+		}
+		size += int64(len(enc.headers[i])) + encoded + int64(len(jsonTrailer))
+	}
+
+	// Closing the array and the message
+	return size + 2, true
+}
+
+// writeTo writes the message to w, encoding attachments
+// straight from their Readers, or from Contents where that
+// has already been cached
+func (enc *messageEncoder) writeTo(w io.Writer) error {
+	if _, err := w.Write(enc.envelope); err != nil {
+		return err
+	}
+	if len(enc.attachments) == 0 {
+		return nil
+	}
+
+	for i, attachment := range enc.attachments {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(enc.headers[i]); err != nil {
+			return err
+		}
+		if err := attachment.writeContent(w); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, jsonTrailer); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "]}")
+	return err
+}
+
+// bytes renders the whole message in memory
+func (enc *messageEncoder) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if size, ok := enc.size(); ok {
+		buf.Grow(int(size))
+	}
+
+	if err := enc.writeTo(&buf); err != nil {
+		return []byte{}, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// body returns the message as a request body
+func (enc *messageEncoder) body() interface{} {
+	return streamedBody(
+		func(w io.Writer) error {
+			return enc.writeTo(newMessageLimitWriter(w))
+		},
+		enc.rewindable(),
+	)
+}
+
+// rewindable reports whether the
+// message can be written more than once
+func (enc *messageEncoder) rewindable() bool {
+	for _, attachment := range enc.attachments {
+		if !attachment.rewindable() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// batchEncoder writes several messages as one batch: a
+// JSON array, or for templated messages an object with
+// the array as its Messages
+type batchEncoder struct {
+	templated bool
+	messages  []*messageEncoder
+}
+
+// size returns the exact length of the encoded batch, and
+// false if any attachment's length cannot be known
+func (be *batchEncoder) size() (int64, bool) {
+	size := int64(len(be.open()) + len(be.close()))
+	for i, enc := range be.messages {
+		n, ok := enc.size()
+		if !ok {
+			return 0, false
+		}
+		if i > 0 {
+			size++
+		}
+		size += n
+	}
+
+	return size, true
+}
+
+func (be *batchEncoder) open() string {
+	if be.templated {
+		return `{"Messages":[`
+	}
+	return "["
+}
+
+func (be *batchEncoder) close() string {
+	if be.templated {
+		return "]}"
+	}
+	return "]"
+}
+
+// writeTo writes the batch to w, holding each
+// message to the single message size limit
+func (be *batchEncoder) writeTo(w io.Writer) error {
+	if _, err := io.WriteString(w, be.open()); err != nil {
+		return err
+	}
+	for i, enc := range be.messages {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := enc.writeTo(newMessageLimitWriter(w)); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, be.close())
+	return err
+}
+
+// body returns the batch as a request body
+func (be *batchEncoder) body() interface{} {
+	rewindable := true
+	for _, enc := range be.messages {
+		rewindable = rewindable && enc.rewindable()
+	}
+
+	return streamedBody(
+		func(w io.Writer) error {
+			return be.writeTo(&sizeLimitWriter{w: w, limit: maxBatchSize, what: "batch"})
+		},
+		rewindable,
+	)
+}
+
+// streamedBody returns a request body that write produces as
+// it is sent. When the content can be written again it is a
+// raw.BodyFunc, so the request can be retried; otherwise it
+// can only be read once.
+func streamedBody(write func(io.Writer) error, rewindable bool) interface{} {
+	if !rewindable {
+		return &streamBody{write: write}
+	}
+
+	return raw.BodyFunc(func() (io.ReadCloser, error) {
+		return &streamBody{write: write}, nil
+	})
+}
+
+// streamBody pipes what write produces to its reader.
+// Writing only starts on the first Read, so a body that
+// is closed unread leaves nothing running.
+type streamBody struct {
+	write func(io.Writer) error
+
+	once sync.Once
+	pr   *io.PipeReader
+}
+
+func (sb *streamBody) start() {
+	pr, pw := io.Pipe()
+	sb.pr = pr
+	go func() {
+		pw.CloseWithError(sb.write(pw))
+	}()
+}
+
+func (sb *streamBody) Read(p []byte) (int, error) {
+	sb.once.Do(sb.start)
+	if sb.pr == nil {
+		return 0, io.ErrClosedPipe
+	}
+
+	return sb.pr.Read(p)
+}
+
+func (sb *streamBody) Close() error {
+	// Closing first stops writing ever starting
+	sb.once.Do(func() {})
+	if sb.pr == nil {
+		return nil
+	}
+
+	return sb.pr.Close()
+}
+
+// sizeLimitWriter fails once more
+// than limit bytes are written through it
+type sizeLimitWriter struct {
+	w       io.Writer
+	limit   int64
+	what    string
+	written int64
+}
+
+func newMessageLimitWriter(w io.Writer) *sizeLimitWriter {
+	return &sizeLimitWriter{w: w, limit: maxMessageSize, what: "message + attachments"}
+}
+
+func (lw *sizeLimitWriter) Write(p []byte) (int, error) {
+	lw.written += int64(len(p))
+	if lw.written > lw.limit {
+		return 0, fmt.Errorf(
+			"%s cannot excede %dMB. Current size: over %d Bytes",
+			lw.what,
+			lw.limit/(1024*1000),
+			lw.limit,
+		)
+	}
+
+	return lw.w.Write(p)
+}
@@ -0,0 +1,326 @@
+package gostmark
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingServer answers the first failures sends with
+// a 503 and then succeeds, recording each body received
+type recordingServer struct {
+	failures int
+
+	mu     sync.Mutex
+	bodies [][]byte
+}
+
+func (rs *recordingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, err := ioutil.ReadAll(r.Body)
+
+	rs.mu.Lock()
+	rs.bodies = append(rs.bodies, b)
+	n := len(rs.bodies)
+	rs.mu.Unlock()
+
+	if err != nil {
+		return
+	}
+	if n <= rs.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"ErrorCode":0,"Message":"Service unavailable"}`))
+		return
+	}
+	response := `{"To":"to@example.com","MessageID":"id","ErrorCode":0,"Message":"OK"}`
+	if strings.HasPrefix(r.URL.Path, "/email/batch") {
+		response = "[" + response + "]"
+	}
+	w.Write([]byte(response))
+}
+
+func (rs *recordingServer) received() [][]byte {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return rs.bodies
+}
+
+func testClient(t *testing.T, rs *recordingServer) Client {
+	t.Helper()
+
+	srv := httptest.NewServer(rs)
+	t.Cleanup(srv.Close)
+
+	return ClientForServerToken(
+		"token",
+		WithHost(srv.URL),
+		WithRetryPolicy(RetryPolicy{
+			MaxAttempts: 3,
+			MinBackoff:  time.Millisecond,
+			MaxBackoff:  5 * time.Millisecond,
+		}),
+	)
+}
+
+func testMessage(attachments ...*Attachment) *Message {
+	m := &Message{
+		From:     EmailAddressForEmail("from@example.com"),
+		Subject:  "Report",
+		HtmlBody: "<p>Attached</p>",
+	}
+	m.AddTo("To", "to@example.com")
+	for _, attachment := range attachments {
+		m.AddAttachment(attachment)
+	}
+
+	return m
+}
+
+// unseekable hides every method but Read
+type unseekable struct {
+	r io.Reader
+}
+
+func (u unseekable) Read(p []byte) (int, error) {
+	return u.r.Read(p)
+}
+
+func TestStreamedSendMatchesMarshalJSON(t *testing.T) {
+	rs := &recordingServer{}
+	client := testClient(t, rs)
+
+	pdf := bytes.Repeat([]byte("%PDF-1.7 0123456789"), 50000)
+	m := testMessage(
+		NewAttachment("report.pdf", "application/pdf", bytes.NewReader(pdf)),
+		&Attachment{Name: "logo.png", ContentType: "image/png", ContentID: "cid:logo", Reader: strings.NewReader("png")},
+	)
+	if _, err := client.SendMessage(m); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	marshalled, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	bodies := rs.received()
+	if len(bodies) != 1 {
+		t.Fatalf("server saw %d requests, want 1", len(bodies))
+	}
+	if !bytes.Equal(bodies[0], marshalled) {
+		t.Errorf("streamed body differs from MarshalJSON:\n%.300s\n%.300s", bodies[0], marshalled)
+	}
+
+	enc, err := m.encoder()
+	if err != nil {
+		t.Fatalf("encoder: %v", err)
+	}
+	if size, ok := enc.size(); !ok || size != int64(len(marshalled)) {
+		t.Errorf("size() = %d, %v, want %d", size, ok, len(marshalled))
+	}
+}
+
+func TestStreamedSendRetriesSeekableAttachments(t *testing.T) {
+	rs := &recordingServer{failures: 1}
+	client := testClient(t, rs)
+
+	m := testMessage(NewAttachment("a.txt", "text/plain", strings.NewReader(strings.Repeat("a", 100000))))
+	if _, err := client.SendMessage(m); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	bodies := rs.received()
+	if len(bodies) != 2 {
+		t.Fatalf("server saw %d requests, want 2", len(bodies))
+	}
+	if !bytes.Equal(bodies[0], bodies[1]) {
+		t.Error("retried body differs from the first")
+	}
+
+	// The attachment still measures its full
+	// size now that it has been read
+	if size, ok := m.Attachments[0].contentSize(); !ok || size != 100000 {
+		t.Errorf("contentSize() after sending = %d, %v", size, ok)
+	}
+}
+
+func TestStreamedSendDoesNotRetryUnseekableAttachments(t *testing.T) {
+	rs := &recordingServer{failures: 1}
+	client := testClient(t, rs)
+
+	m := testMessage(NewAttachment("a.txt", "text/plain", unseekable{io.MultiReader(
+		strings.NewReader("first part "),
+		strings.NewReader("second part"),
+	)}))
+	_, err := client.SendMessage(m)
+
+	apiErr, ok := AsAPIError(err)
+	if !ok || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want the 503 *APIError", err)
+	}
+	if n := len(rs.received()); n != 1 {
+		t.Errorf("server saw %d requests, want 1", n)
+	}
+}
+
+func TestSendRejectsOversizedMessages(t *testing.T) {
+	rs := &recordingServer{}
+	client := testClient(t, rs)
+
+	// A known size is rejected before anything is sent
+	big := bytes.NewReader(make([]byte, 8*1024*1024))
+	_, err := client.SendMessage(testMessage(NewAttachment("big.pdf", "application/pdf", big)))
+	var problems ValidationProblems
+	if !errors.As(err, &problems) || !strings.Contains(err.Error(), "10MB") {
+		t.Fatalf("err = %v, want a 10MB validation problem", err)
+	}
+	if n := len(rs.received()); n != 0 {
+		t.Fatalf("server saw %d requests, want 0", n)
+	}
+
+	// An unknown size fails as it is streamed, without retrying
+	unknown := unseekable{bytes.NewReader(make([]byte, 8*1024*1024))}
+	_, err = client.SendMessage(testMessage(NewAttachment("big.pdf", "application/pdf", unknown)))
+	if err == nil || !strings.Contains(err.Error(), "10MB") {
+		t.Fatalf("err = %v, want the 10MB limit", err)
+	}
+	if n := len(rs.received()); n > 1 {
+		t.Errorf("server saw %d requests, want at most 1", n)
+	}
+}
+
+func TestSendRejectsOversizedRewindableStream(t *testing.T) {
+	rs := &recordingServer{}
+	client := testClient(t, rs)
+
+	// The size is wrong, so the overflow is only found
+	// while streaming; it must not be retried
+	attachment := NewAttachment("big.pdf", "application/pdf", bytes.NewReader(make([]byte, 8*1024*1024)))
+	attachment.Size = 1
+	_, err := client.SendMessage(testMessage(attachment))
+	if err == nil || !strings.Contains(err.Error(), "10MB") {
+		t.Fatalf("err = %v, want the 10MB limit", err)
+	}
+	if n := len(rs.received()); n > 1 {
+		t.Errorf("server saw %d requests, want at most 1", n)
+	}
+}
+
+func TestStreamedBatchMatchesMarshal(t *testing.T) {
+	templated := func(attachment *Attachment) *Message {
+		m := testMessage(attachment)
+		m.HtmlBody = ""
+		m.TemplateAlias = "welcome"
+		return m
+	}
+
+	for _, tc := range []struct {
+		name     string
+		messages func() []*Message
+		packet   func([]*Message) interface{}
+	}{
+		{
+			name: "plain",
+			messages: func() []*Message {
+				return []*Message{
+					testMessage(NewAttachment("a.txt", "text/plain", strings.NewReader("first"))),
+					testMessage(),
+				}
+			},
+			packet: func(messages []*Message) interface{} { return messages },
+		},
+		{
+			name: "templated",
+			messages: func() []*Message {
+				return []*Message{
+					templated(NewAttachment("a.txt", "text/plain", strings.NewReader("first"))),
+					templated(NewAttachment("b.txt", "text/plain", strings.NewReader("second"))),
+				}
+			},
+			packet: func(messages []*Message) interface{} {
+				return map[string]interface{}{"Messages": messages}
+			},
+		},
+	} {
+		rs := &recordingServer{}
+		client := testClient(t, rs)
+
+		messages := tc.messages()
+		if _, err := client.SendMessages(messages); err != nil {
+			t.Fatalf("%s: SendMessages: %v", tc.name, err)
+		}
+		for i, m := range messages {
+			for _, attachment := range m.Attachments {
+				if attachment.contents != "" {
+					t.Errorf("%s: message %d kept its attachment contents after sending", tc.name, i)
+				}
+			}
+		}
+
+		marshalled, err := json.Marshal(tc.packet(messages))
+		if err != nil {
+			t.Fatalf("%s: Marshal: %v", tc.name, err)
+		}
+		bodies := rs.received()
+		if len(bodies) != 1 {
+			t.Fatalf("%s: server saw %d requests, want 1", tc.name, len(bodies))
+		}
+		if !bytes.Equal(bodies[0], marshalled) {
+			t.Errorf("%s: streamed batch differs from Marshal:\n%s\n%s", tc.name, bodies[0], marshalled)
+		}
+		for i, m := range messages {
+			for _, attachment := range m.Attachments {
+				if attachment.contents != "" {
+					t.Errorf("%s: message %d kept its attachment contents after marshalling", tc.name, i)
+				}
+			}
+		}
+	}
+}
+
+func TestStreamedBatchRetriesSeekableAttachments(t *testing.T) {
+	rs := &recordingServer{failures: 1}
+	client := testClient(t, rs)
+
+	messages := []*Message{
+		testMessage(NewAttachment("a.txt", "text/plain", strings.NewReader(strings.Repeat("a", 100000)))),
+		testMessage(NewAttachment("b.txt", "text/plain", strings.NewReader(strings.Repeat("b", 100000)))),
+	}
+	if _, err := client.SendMessages(messages); err != nil {
+		t.Fatalf("SendMessages: %v", err)
+	}
+
+	bodies := rs.received()
+	if len(bodies) != 2 {
+		t.Fatalf("server saw %d requests, want 2", len(bodies))
+	}
+	if !bytes.Equal(bodies[0], bodies[1]) {
+		t.Error("retried batch differs from the first")
+	}
+}
+
+func TestStreamedBatchDoesNotRetryUnseekableAttachments(t *testing.T) {
+	rs := &recordingServer{failures: 1}
+	client := testClient(t, rs)
+
+	messages := []*Message{
+		testMessage(NewAttachment("a.txt", "text/plain", strings.NewReader("seekable"))),
+		testMessage(NewAttachment("b.txt", "text/plain", unseekable{strings.NewReader("unseekable")})),
+	}
+	_, err := client.SendMessages(messages)
+
+	apiErr, ok := AsAPIError(err)
+	if !ok || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want the 503 *APIError", err)
+	}
+	if n := len(rs.received()); n != 1 {
+		t.Errorf("server saw %d requests, want 1", n)
+	}
+}
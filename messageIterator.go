@@ -0,0 +1,204 @@
+package gostmark
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// maxSearchDepth is the largest offset+count
+	// Postmark serves for a single message search
+	maxSearchDepth = 10000
+
+	// messageRetention is how far back
+	// Postmark keeps message history
+	messageRetention = 45 * 24 * time.Hour
+
+	defaultSearchPageSize = 500
+)
+
+// OutboundMessageIterator walks every outbound message matching
+// a search, paging automatically. When a search matches more
+// messages than Postmark will page through, it is split into
+// narrower FromDate/ToDate windows, newest first.
+//
+//	it := client.IterateOutboundMessages(ctx, packet)
+//	for it.Next() {
+//		message := it.Message()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type OutboundMessageIterator struct {
+	ctx    context.Context
+	client Client
+	packet MessageSearchPacket
+
+	// windows is a stack of date ranges still to be
+	// searched, the newest on top
+	windows []searchWindow
+
+	page    []SearchResult
+	current SearchResult
+	err     error
+}
+
+type searchWindow struct {
+	from time.Time
+	to   time.Time
+
+	// skip is how many of the newest messages in the window
+	// are still to be passed over for packet.Offset, and
+	// offset how far into the window paging has reached
+	skip   int
+	offset int
+
+	// started is set once the window is known
+	// to fit under maxSearchDepth
+	started bool
+	total   int
+}
+
+// IterateOutboundMessages returns an iterator over every outbound
+// message matching packet. Count sets the page size and Offset
+// the number of the newest messages to skip, which may exceed
+// Postmark's paging limit and carries across split windows. A
+// zero FromDate or ToDate is taken as the limit of Postmark's
+// message retention.
+func (c Client) IterateOutboundMessages(ctx context.Context, packet MessageSearchPacket) *OutboundMessageIterator {
+	to := packet.ToDate
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := packet.FromDate
+	if from.IsZero() {
+		from = to.Add(-messageRetention)
+	}
+	if packet.Count <= 0 || packet.Count > defaultSearchPageSize {
+		packet.Count = defaultSearchPageSize
+	}
+	skip := packet.Offset
+	if skip < 0 {
+		skip = 0
+	}
+
+	return &OutboundMessageIterator{
+		ctx:    ctx,
+		client: c,
+		packet: packet,
+		windows: []searchWindow{
+			{
+				from: from.Truncate(time.Second),
+				to:   to.Truncate(time.Second),
+				skip: skip,
+			},
+		},
+	}
+}
+
+// Next advances to the next message, fetching pages as
+// needed. It returns false when the search is exhausted
+// or a request fails; check Err to tell which.
+func (it *OutboundMessageIterator) Next() bool {
+	for it.err == nil {
+		if len(it.page) > 0 {
+			it.current = it.page[0]
+			it.page = it.page[1:]
+			return true
+		}
+		if len(it.windows) == 0 {
+			return false
+		}
+
+		it.fetch()
+	}
+
+	return false
+}
+
+// Message returns the message Next advanced to
+func (it *OutboundMessageIterator) Message() SearchResult {
+	return it.current
+}
+
+// Err returns the error that stopped iteration, if any
+func (it *OutboundMessageIterator) Err() error {
+	return it.err
+}
+
+// fetch requests the next page of the window on top of the
+// stack, splitting the window first if it is too deep to page
+func (it *OutboundMessageIterator) fetch() {
+	w := &it.windows[len(it.windows)-1]
+
+	offset, count := w.offset, it.packet.Count
+	if !w.started {
+		offset = w.skip
+		if offset >= maxSearchDepth {
+			// Too deep to page to; only the total is
+			// needed to split or pass over the window
+			offset, count = 0, 1
+		}
+	}
+	if offset+count > maxSearchDepth {
+		count = maxSearchDepth - offset
+	}
+
+	packet := it.packet
+	packet.FromDate = w.from
+	packet.ToDate = w.to
+	packet.Offset = offset
+	packet.Count = count
+
+	results, err := it.client.SearchMessagesContext(it.ctx, true, packet)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	if !w.started {
+		switch {
+		case results.TotalCount <= w.skip:
+			// The whole window is skipped; carry what is
+			// left of the offset on to the next, older one
+			remaining := w.skip - results.TotalCount
+			it.windows = it.windows[:len(it.windows)-1]
+			if len(it.windows) > 0 {
+				it.windows[len(it.windows)-1].skip += remaining
+			}
+			return
+
+		case results.TotalCount > maxSearchDepth:
+			if !w.to.After(w.from) {
+				it.err = fmt.Errorf(
+					"%d messages sent at %s exceed the search limit of %d",
+					results.TotalCount,
+					w.from.Format(time.RFC3339),
+					maxSearchDepth,
+				)
+				return
+			}
+
+			// Replace the window with its two halves, pushing
+			// the newer one last so it is searched first. The
+			// offset still to skip starts in the newer half.
+			mid := w.from.Add(w.to.Sub(w.from) / 2).Truncate(time.Second)
+			older := searchWindow{from: w.from, to: mid}
+			newer := searchWindow{from: mid.Add(time.Second), to: w.to, skip: w.skip}
+			it.windows = append(it.windows[:len(it.windows)-1], older, newer)
+			return
+		}
+
+		w.started = true
+		w.offset = w.skip
+	}
+
+	w.total = results.TotalCount
+	w.offset += len(results.Messages)
+	if len(results.Messages) == 0 || w.offset >= w.total || w.offset >= maxSearchDepth {
+		it.windows = it.windows[:len(it.windows)-1]
+	}
+
+	it.page = results.Messages
+}
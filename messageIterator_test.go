@@ -0,0 +1,189 @@
+package gostmark
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeOutbound serves /messages/outbound over messages,
+// which are sorted newest first, enforcing Postmark's
+// limit on how deep a search can be paged
+func fakeOutbound(t *testing.T, messages []SearchResult) (Client, *int) {
+	t.Helper()
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		q := r.URL.Query()
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		count, _ := strconv.Atoi(q.Get("count"))
+		if count <= 0 || count > 500 || offset < 0 || offset+count > maxSearchDepth {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"ErrorCode":1,"Message":"bad paging offset=%d count=%d"}`, offset, count)
+			return
+		}
+		from, _ := time.Parse(time.RFC3339, q.Get("fromdate"))
+		to, _ := time.Parse(time.RFC3339, q.Get("todate"))
+
+		var matched []SearchResult
+		for _, m := range messages {
+			if !m.ReceivedAt.Before(from) && !m.ReceivedAt.After(to) {
+				matched = append(matched, m)
+			}
+		}
+
+		page := []SearchResult{}
+		if offset < len(matched) {
+			end := offset + count
+			if end > len(matched) {
+				end = len(matched)
+			}
+			page = matched[offset:end]
+		}
+		json.NewEncoder(w).Encode(SearchResults{TotalCount: len(matched), Messages: page})
+	}))
+	t.Cleanup(srv.Close)
+
+	return ClientForServerToken("token", WithHost(srv.URL)), &requests
+}
+
+// sentMessages returns n messages sent a few
+// seconds apart before now, newest first
+func sentMessages(now time.Time, n int) []SearchResult {
+	messages := make([]SearchResult, n)
+	for i := range messages {
+		messages[i] = SearchResult{
+			MessageID:  strconv.Itoa(i),
+			ReceivedAt: now.Add(-time.Duration(i*7+1) * time.Second),
+		}
+	}
+
+	return messages
+}
+
+func collect(t *testing.T, it *OutboundMessageIterator) []string {
+	t.Helper()
+
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Message().MessageID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+
+	return ids
+}
+
+func TestIterateOutboundMessagesSplitsDeepSearches(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	messages := sentMessages(now, 25000)
+	client, requests := fakeOutbound(t, messages)
+
+	ids := collect(t, client.IterateOutboundMessages(context.Background(), MessageSearchPacket{
+		ToDate: now,
+	}))
+
+	if len(ids) != len(messages) {
+		t.Fatalf("got %d messages, want %d", len(ids), len(messages))
+	}
+	for i, id := range ids {
+		if id != messages[i].MessageID {
+			t.Fatalf("message %d is %s, want %s", i, id, messages[i].MessageID)
+		}
+	}
+	if *requests < len(messages)/defaultSearchPageSize {
+		t.Errorf("only %d requests made", *requests)
+	}
+}
+
+func TestIterateOutboundMessagesOffset(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	messages := sentMessages(now, 25000)
+	client, _ := fakeOutbound(t, messages)
+
+	// Offsets both within and past the paging limit,
+	// which must carry across split windows
+	for _, offset := range []int{0, 120, 9990, 12345, 24999, 25000, 30000} {
+		ids := collect(t, client.IterateOutboundMessages(context.Background(), MessageSearchPacket{
+			ToDate: now,
+			Offset: offset,
+			Count:  100,
+		}))
+
+		want := 0
+		if offset < len(messages) {
+			want = len(messages) - offset
+		}
+		if len(ids) != want {
+			t.Fatalf("offset %d: got %d messages, want %d", offset, len(ids), want)
+		}
+		if want > 0 && ids[0] != messages[offset].MessageID {
+			t.Errorf("offset %d: first message is %s, want %s", offset, ids[0], messages[offset].MessageID)
+		}
+	}
+}
+
+func TestIterateOutboundMessagesSmallSearch(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	messages := sentMessages(now, 30)
+	client, requests := fakeOutbound(t, messages)
+
+	ids := collect(t, client.IterateOutboundMessages(context.Background(), MessageSearchPacket{
+		ToDate: now,
+		Count:  10,
+	}))
+
+	if len(ids) != 30 {
+		t.Fatalf("got %d messages, want 30", len(ids))
+	}
+	if *requests != 3 {
+		t.Errorf("made %d requests, want 3", *requests)
+	}
+}
+
+func TestIterateOutboundMessagesSplitsOneSecondWindow(t *testing.T) {
+	sent := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	// 20000 messages over two whole seconds, each
+	// second small enough to page through alone
+	messages := make([]SearchResult, 20000)
+	for i := range messages {
+		at := sent.Add(time.Second)
+		if i >= 10000 {
+			at = sent
+		}
+		messages[i] = SearchResult{MessageID: strconv.Itoa(i), ReceivedAt: at}
+	}
+	client, _ := fakeOutbound(t, messages)
+
+	ids := collect(t, client.IterateOutboundMessages(context.Background(), MessageSearchPacket{
+		FromDate: sent,
+		ToDate:   sent.Add(time.Second),
+	}))
+	if len(ids) != len(messages) {
+		t.Fatalf("got %d messages, want %d", len(ids), len(messages))
+	}
+
+	// A single second can go no further
+	crowded := make([]SearchResult, maxSearchDepth+1)
+	for i := range crowded {
+		crowded[i] = SearchResult{MessageID: strconv.Itoa(i), ReceivedAt: sent}
+	}
+	client, _ = fakeOutbound(t, crowded)
+	it := client.IterateOutboundMessages(context.Background(), MessageSearchPacket{
+		FromDate: sent,
+		ToDate:   sent.Add(time.Second),
+	})
+	for it.Next() {
+	}
+	if it.Err() == nil {
+		t.Error("expected an error for more than 10000 messages in one second")
+	}
+}
@@ -1,6 +1,7 @@
 package gostmark
 
 import (
+	"net/mail"
 	"net/url"
 	"strconv"
 	"time"
@@ -15,6 +16,12 @@ type MessageSearchPacket struct {
 	FromDate  time.Time
 	Subject   string
 
+	MessageStream string
+
+	// Metadata matches messages sent with
+	// all of these metadata values
+	Metadata map[string]string
+
 	Count  int
 	Offset int
 }
@@ -37,6 +44,9 @@ type SearchResult struct {
 	Status     string         `json:"Status"`
 	TrackOpens bool           `json:"TrackOpens"`
 	TrackLinks string         `json:"TrackLinks"`
+
+	MessageStream string            `json:"MessageStream"`
+	Metadata      map[string]string `json:"Metadata"`
 }
 
 // MessageSearchPacket returns the search packet as url.Values
@@ -70,6 +80,152 @@ func (msp MessageSearchPacket) AsValues() url.Values {
 	if msp.Subject != "" {
 		vals.Add("subject", msp.Subject)
 	}
+	if msp.MessageStream != "" {
+		vals.Add("messagestream", msp.MessageStream)
+	}
+	for key, value := range msp.Metadata {
+		vals.Add("metadata_"+key, value)
+	}
 
 	return vals
 }
+
+// InboundMessageSearchPacket filters a search of
+// inbound messages. Status may be Blocked, Processed,
+// Queued, Failed or Scheduled.
+type InboundMessageSearchPacket struct {
+	Recipient   string
+	FromEmail   EmailAddress
+	Tag         string
+	Subject     string
+	MailboxHash string
+	Status      MessageStatus
+	ToDate      time.Time
+	FromDate    time.Time
+
+	Count  int
+	Offset int
+}
+
+type InboundSearchResults struct {
+	TotalCount      int                   `json:"TotalCount"`
+	InboundMessages []InboundSearchResult `json:"InboundMessages"`
+}
+
+type InboundSearchResult struct {
+	From              string           `json:"From"`
+	FromName          string           `json:"FromName"`
+	FromFull          InboundAddress   `json:"FromFull"`
+	To                string           `json:"To"`
+	ToFull            []InboundAddress `json:"ToFull"`
+	Cc                string           `json:"Cc"`
+	CcFull            []InboundAddress `json:"CcFull"`
+	ReplyTo           string           `json:"ReplyTo"`
+	OriginalRecipient string           `json:"OriginalRecipient"`
+	Subject           string           `json:"Subject"`
+	Date              string           `json:"Date"`
+	MailboxHash       string           `json:"MailboxHash"`
+	Tag               string           `json:"Tag"`
+	MessageID         string           `json:"MessageID"`
+	Status            string           `json:"Status"`
+	Attachments       []AttachmentInfo `json:"Attachments"`
+}
+
+// InboundAddress is an address as reported on inbound
+// messages, including the hash after a "+" in the mailbox
+type InboundAddress struct {
+	Email       string `json:"Email"`
+	Name        string `json:"Name"`
+	MailboxHash string `json:"MailboxHash"`
+}
+
+// EmailAddress drops the mailbox hash
+func (ia InboundAddress) EmailAddress() EmailAddress {
+	return EmailAddress{
+		Name:  ia.Name,
+		Email: ia.Email,
+	}
+}
+
+// AsValues returns the search packet as url.Values
+func (msp InboundMessageSearchPacket) AsValues() url.Values {
+	vals := make(url.Values)
+	vals.Add("offset", strconv.Itoa(msp.Offset))
+
+	if msp.Count == 0 {
+		msp.Count = 500
+	}
+	vals.Add("count", strconv.Itoa(msp.Count))
+
+	if msp.Recipient != "" {
+		vals.Add("recipient", msp.Recipient)
+	}
+	if msp.FromEmail.Email != "" {
+		vals.Add("fromemail", msp.FromEmail.Email)
+	}
+	if msp.Tag != "" {
+		vals.Add("tag", msp.Tag)
+	}
+	if msp.Subject != "" {
+		vals.Add("subject", msp.Subject)
+	}
+	if msp.MailboxHash != "" {
+		vals.Add("mailboxhash", msp.MailboxHash)
+	}
+	if msp.Status != "" {
+		vals.Add("status", string(msp.Status))
+	}
+	if !msp.FromDate.IsZero() {
+		vals.Add("fromdate", msp.FromDate.Format(time.RFC3339))
+	}
+	if !msp.ToDate.IsZero() {
+		vals.Add("todate", msp.ToDate.Format(time.RFC3339))
+	}
+
+	return vals
+}
+
+// inboundPacket converts an outbound search
+// packet for use against inbound messages
+func (msp MessageSearchPacket) inboundPacket() InboundMessageSearchPacket {
+	return InboundMessageSearchPacket{
+		Recipient: msp.Recipient,
+		FromEmail: msp.FromEmail,
+		Tag:       msp.Tag,
+		Subject:   msp.Subject,
+		Status:    msp.Status,
+		ToDate:    msp.ToDate,
+		FromDate:  msp.FromDate,
+		Count:     msp.Count,
+		Offset:    msp.Offset,
+	}
+}
+
+// searchResult flattens an inbound result into the
+// shape shared with outbound searches
+func (isr InboundSearchResult) searchResult() SearchResult {
+	sr := SearchResult{
+		Tag:       isr.Tag,
+		MessageID: isr.MessageID,
+		From:      isr.From,
+		Subject:   isr.Subject,
+		Status:    isr.Status,
+	}
+	if isr.OriginalRecipient != "" {
+		sr.Recipients = []string{isr.OriginalRecipient}
+	}
+	if from, err := isr.FromFull.EmailAddress().String(); err == nil {
+		sr.From = from
+	}
+	for _, to := range isr.ToFull {
+		sr.To = append(sr.To, to.EmailAddress())
+	}
+	for _, cc := range isr.CcFull {
+		sr.Cc = append(sr.Cc, cc.EmailAddress())
+	}
+	if date, err := mail.ParseDate(isr.Date); err == nil {
+		sr.ReceivedAt = date
+	}
+
+	return sr
+}
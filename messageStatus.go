@@ -6,4 +6,9 @@ const (
 	Queued    MessageStatus = "queued"
 	Sent      MessageStatus = "sent"
 	Processed MessageStatus = "processed"
+
+	// Inbound only
+	Blocked   MessageStatus = "blocked"
+	Failed    MessageStatus = "failed"
+	Scheduled MessageStatus = "scheduled"
 )
@@ -0,0 +1,224 @@
+package gostmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/themartorana/Gostmark/v2/raw"
+)
+
+// MessageStreamType is the kind of mail a stream carries
+type MessageStreamType string
+
+const (
+	MessageStreamTypeTransactional MessageStreamType = "Transactional"
+	MessageStreamTypeBroadcasts    MessageStreamType = "Broadcasts"
+	MessageStreamTypeInbound       MessageStreamType = "Inbound"
+)
+
+// UnsubscribeHandlingType controls who manages
+// unsubscribe links for a stream
+type UnsubscribeHandlingType string
+
+const (
+	UnsubscribeHandlingNone     UnsubscribeHandlingType = "None"
+	UnsubscribeHandlingPostmark UnsubscribeHandlingType = "Postmark"
+	UnsubscribeHandlingCustom   UnsubscribeHandlingType = "Custom"
+)
+
+// Default stream IDs every server is created with
+const (
+	DefaultTransactionalStream = "outbound"
+	DefaultInboundStream       = "inbound"
+)
+
+type MessageStream struct {
+	ID                string
+	ServerID          int
+	Name              string
+	Description       string
+	MessageStreamType MessageStreamType
+
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	ArchivedAt        time.Time
+	ExpectedPurgeDate time.Time
+
+	SubscriptionManagementConfiguration SubscriptionManagementConfiguration
+}
+
+type SubscriptionManagementConfiguration struct {
+	UnsubscribeHandlingType UnsubscribeHandlingType `json:",omitempty"`
+}
+
+// MessageStreamPacket creates or edits a stream. ID and
+// MessageStreamType are only used on creation.
+type MessageStreamPacket struct {
+	ID                                  string                               `json:",omitempty"`
+	Name                                string                               `json:",omitempty"`
+	MessageStreamType                   MessageStreamType                    `json:",omitempty"`
+	Description                         string                               `json:",omitempty"`
+	SubscriptionManagementConfiguration *SubscriptionManagementConfiguration `json:",omitempty"`
+}
+
+// MessageStreamArchive is returned when a stream is archived.
+// The stream can be unarchived until ExpectedPurgeDate.
+type MessageStreamArchive struct {
+	ID                string
+	ServerID          int
+	ExpectedPurgeDate time.Time
+}
+
+func messageStreamPath(streamID string, action string) string {
+	return fmt.Sprintf(
+		"/message-streams/%s%s",
+		url.PathEscape(streamID),
+		action,
+	)
+}
+
+// ListMessageStreams lists the streams on the server. An
+// empty streamType lists streams of every type.
+func (c Client) ListMessageStreams(streamType MessageStreamType, includeArchived bool) ([]MessageStream, error) {
+	return c.ListMessageStreamsContext(context.Background(), streamType, includeArchived)
+}
+
+// ListMessageStreamsContext is ListMessageStreams
+// with a caller-supplied context
+func (c Client) ListMessageStreamsContext(ctx context.Context, streamType MessageStreamType, includeArchived bool) ([]MessageStream, error) {
+	query := url.Values{
+		"MessageStreamType":      {"All"},
+		"IncludeArchivedStreams": {strconv.FormatBool(includeArchived)},
+	}
+	if streamType != "" {
+		query.Set("MessageStreamType", string(streamType))
+	}
+
+	var list struct {
+		MessageStreams []MessageStream
+		TotalCount     int
+	}
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodGet,
+			Path:    "/message-streams",
+			Headers: c.serverHeaders(),
+			Query:   query,
+		},
+		&list,
+	)
+	if err != nil {
+		return []MessageStream{}, err
+	}
+
+	return list.MessageStreams, nil
+}
+
+// GetMessageStream retrieves a stream by ID
+func (c Client) GetMessageStream(streamID string) (MessageStream, error) {
+	return c.GetMessageStreamContext(context.Background(), streamID)
+}
+
+// GetMessageStreamContext is GetMessageStream
+// with a caller-supplied context
+func (c Client) GetMessageStreamContext(ctx context.Context, streamID string) (MessageStream, error) {
+	return c.messageStreamRequest(ctx, http.MethodGet, messageStreamPath(streamID, ""), nil)
+}
+
+// CreateMessageStream adds a stream to the server
+func (c Client) CreateMessageStream(packet MessageStreamPacket) (MessageStream, error) {
+	return c.CreateMessageStreamContext(context.Background(), packet)
+}
+
+// CreateMessageStreamContext is CreateMessageStream
+// with a caller-supplied context
+func (c Client) CreateMessageStreamContext(ctx context.Context, packet MessageStreamPacket) (MessageStream, error) {
+	if packet.ID == "" {
+		return MessageStream{}, errors.New("message stream ID required")
+	}
+	if packet.Name == "" {
+		return MessageStream{}, errors.New("message stream Name required")
+	}
+	if packet.MessageStreamType == "" {
+		return MessageStream{}, errors.New("message stream MessageStreamType required")
+	}
+
+	return c.messageStreamRequest(ctx, http.MethodPost, "/message-streams", packet)
+}
+
+// EditMessageStream updates the name, description or
+// unsubscribe handling of a stream
+func (c Client) EditMessageStream(streamID string, packet MessageStreamPacket) (MessageStream, error) {
+	return c.EditMessageStreamContext(context.Background(), streamID, packet)
+}
+
+// EditMessageStreamContext is EditMessageStream
+// with a caller-supplied context
+func (c Client) EditMessageStreamContext(ctx context.Context, streamID string, packet MessageStreamPacket) (MessageStream, error) {
+	// Neither can be changed once the stream exists
+	packet.ID = ""
+	packet.MessageStreamType = ""
+	return c.messageStreamRequest(ctx, http.MethodPatch, messageStreamPath(streamID, ""), packet)
+}
+
+// ArchiveMessageStream archives a stream. Its messages
+// are purged after the returned ExpectedPurgeDate.
+func (c Client) ArchiveMessageStream(streamID string) (MessageStreamArchive, error) {
+	return c.ArchiveMessageStreamContext(context.Background(), streamID)
+}
+
+// ArchiveMessageStreamContext is ArchiveMessageStream
+// with a caller-supplied context
+func (c Client) ArchiveMessageStreamContext(ctx context.Context, streamID string) (MessageStreamArchive, error) {
+	var msa MessageStreamArchive
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodPost,
+			Path:    messageStreamPath(streamID, "/archive"),
+			Headers: c.serverHeaders(),
+		},
+		&msa,
+	)
+	if err != nil {
+		return MessageStreamArchive{}, err
+	}
+
+	return msa, nil
+}
+
+// UnarchiveMessageStream restores an archived stream
+func (c Client) UnarchiveMessageStream(streamID string) (MessageStream, error) {
+	return c.UnarchiveMessageStreamContext(context.Background(), streamID)
+}
+
+// UnarchiveMessageStreamContext is UnarchiveMessageStream
+// with a caller-supplied context
+func (c Client) UnarchiveMessageStreamContext(ctx context.Context, streamID string) (MessageStream, error) {
+	return c.messageStreamRequest(ctx, http.MethodPost, messageStreamPath(streamID, "/unarchive"), nil)
+}
+
+func (c Client) messageStreamRequest(ctx context.Context, method, path string, body interface{}) (MessageStream, error) {
+	var ms MessageStream
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  method,
+			Path:    path,
+			Headers: c.serverHeaders(),
+			Body:    body,
+		},
+		&ms,
+	)
+	if err != nil {
+		return MessageStream{}, err
+	}
+
+	return ms, nil
+}
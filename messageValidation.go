@@ -0,0 +1,267 @@
+package gostmark
+
+import (
+	"fmt"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSubjectLength is the longest
+// subject Postmark accepts, in characters
+const maxSubjectLength = 2000
+
+// Limits on Message.Metadata
+const (
+	maxMetadataFields      = 10
+	maxMetadataKeyLength   = 20
+	maxMetadataValueLength = 80
+)
+
+// reservedHeaders are set from the Message fields
+// or by Postmark itself, and cannot be added
+// through Headers
+var reservedHeaders = map[string]bool{
+	"From":                      true,
+	"To":                        true,
+	"Cc":                        true,
+	"Bcc":                       true,
+	"Subject":                   true,
+	"Reply-To":                  true,
+	"Sender":                    true,
+	"Return-Path":               true,
+	"Date":                      true,
+	"Mime-Version":              true,
+	"Content-Type":              true,
+	"Content-Transfer-Encoding": true,
+	"Dkim-Signature":            true,
+}
+
+type ValidationSeverity string
+
+const (
+	// SeverityError problems stop the message being sent
+	SeverityError ValidationSeverity = "error"
+
+	// SeverityWarning problems are sent anyway,
+	// but probably not as intended
+	SeverityWarning ValidationSeverity = "warning"
+)
+
+// ValidationProblem is a single issue found by Message.Validate
+type ValidationProblem struct {
+	// Field names the part of the message at
+	// fault, such as "Cc[3]" or "Attachments[0]"
+	Field    string
+	Severity ValidationSeverity
+	Message  string
+}
+
+func (vp ValidationProblem) Error() string {
+	return fmt.Sprintf("%s: %s", vp.Field, vp.Message)
+}
+
+// ValidationProblems is every issue found by Message.Validate
+type ValidationProblems []ValidationProblem
+
+// Error joins every problem into one message
+func (vps ValidationProblems) Error() string {
+	messages := make([]string, 0, len(vps))
+	for _, problem := range vps {
+		messages = append(messages, problem.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Errors returns the problems that stop the message being sent
+func (vps ValidationProblems) Errors() ValidationProblems {
+	return vps.withSeverity(SeverityError)
+}
+
+// Warnings returns the problems the message is sent despite
+func (vps ValidationProblems) Warnings() ValidationProblems {
+	return vps.withSeverity(SeverityWarning)
+}
+
+// Err returns the problems that stop the message being
+// sent as an error, or nil if it can be sent
+func (vps ValidationProblems) Err() error {
+	if errs := vps.Errors(); len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (vps ValidationProblems) withSeverity(severity ValidationSeverity) ValidationProblems {
+	var matching ValidationProblems
+	for _, problem := range vps {
+		if problem.Severity == severity {
+			matching = append(matching, problem)
+		}
+	}
+
+	return matching
+}
+
+// Validate checks the message against Postmark's rules,
+// returning every problem found rather than only the first.
+// Problems with SeverityError stop the message being sent;
+// MarshalJSON and SendMessage fail with them.
+func (m *Message) Validate() ValidationProblems {
+	problems, _ := m.validate()
+	return problems
+}
+
+// validate is Validate, also returning the encoder built to
+// measure the message so that sending can reuse it. The
+// encoder is nil when there are errors.
+func (m *Message) validate() (ValidationProblems, *messageEncoder) {
+	var problems ValidationProblems
+	add := func(severity ValidationSeverity, field, format string, args ...interface{}) {
+		problems = append(problems, ValidationProblem{
+			Field:    field,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+	checkAddress := func(field string, address EmailAddress) {
+		if err := validateAddress(address); err != nil {
+			add(SeverityError, field, "%v", err)
+		}
+	}
+
+	// Addresses
+	if m.From.Email == "" {
+		add(SeverityError, "From", "from EmailAddress required")
+	} else {
+		checkAddress("From", m.From)
+	}
+	if m.ReplyTo.Email != "" {
+		checkAddress("ReplyTo", m.ReplyTo)
+	}
+	if len(m.To) == 0 {
+		add(SeverityError, "To", "to EmailAddress required")
+	}
+	for _, list := range []struct {
+		field     string
+		addresses []EmailAddress
+	}{
+		{"To", m.To},
+		{"Cc", m.Cc},
+		{"Bcc", m.Bcc},
+	} {
+		for i, address := range list.addresses {
+			checkAddress(fmt.Sprintf("%s[%d]", list.field, i), address)
+		}
+	}
+	if recipients := len(m.To) + len(m.Cc) + len(m.Bcc); recipients > maxRecipients {
+		add(
+			SeverityError, "To",
+			"to, cc and bcc cannot contain more than %d entries combined, found %d",
+			maxRecipients, recipients,
+		)
+	}
+
+	// Content
+	if length := utf8.RuneCountInString(m.Subject); length > maxSubjectLength {
+		add(SeverityError, "Subject", "subject cannot be longer than %d characters, found %d", maxSubjectLength, length)
+	}
+	if m.HtmlBody == "" && m.TextBody == "" && !m.usesTemplate() {
+		add(SeverityError, "HtmlBody", "HtmlBody and TextBody cannot both be blank")
+	}
+	if m.TemplateId != 0 && m.TemplateAlias != "" {
+		add(SeverityWarning, "TemplateAlias", "TemplateAlias is ignored when TemplateId is set")
+	}
+	if m.TrackOpens && m.HtmlBody == "" && !m.usesTemplate() {
+		add(SeverityWarning, "TrackOpens", "TrackOpens is a NOOP on messages without an HtmlBody set")
+	}
+	if m.TrackLinks != "" && !m.TrackLinks.Valid() {
+		add(SeverityError, "TrackLinks", "invalid TrackLinks value %q", m.TrackLinks)
+	}
+	for i, header := range m.Headers {
+		field := fmt.Sprintf("Headers[%d]", i)
+		name := textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(header.Name))
+		switch {
+		case name == "":
+			add(SeverityError, field, "header name required")
+		case reservedHeaders[name]:
+			add(SeverityError, field, "the %s header is reserved and cannot be set through Headers", name)
+		}
+	}
+
+	// Metadata, in key order so
+	// problems are reported stably
+	if len(m.Metadata) > maxMetadataFields {
+		add(SeverityError, "Metadata", "metadata cannot contain more than %d fields, found %d", maxMetadataFields, len(m.Metadata))
+	}
+	keys := make([]string, 0, len(m.Metadata))
+	for key := range m.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		field := fmt.Sprintf("Metadata[%q]", key)
+		if key == "" {
+			add(SeverityError, field, "metadata key cannot be blank")
+		} else if length := utf8.RuneCountInString(key); length > maxMetadataKeyLength {
+			add(SeverityError, field, "metadata key cannot be longer than %d characters, found %d", maxMetadataKeyLength, length)
+		}
+		if length := utf8.RuneCountInString(m.Metadata[key]); length > maxMetadataValueLength {
+			add(SeverityError, field, "metadata value cannot be longer than %d characters, found %d", maxMetadataValueLength, length)
+		}
+	}
+
+	// Attachments
+	for i, attachment := range m.Attachments {
+		field := fmt.Sprintf("Attachments[%d]", i)
+		if attachment == nil {
+			add(SeverityError, field, "attachment cannot be nil")
+			continue
+		}
+		if attachment.Name == "" {
+			add(SeverityError, field, "attachment Name required")
+		} else if err := checkAttachmentName(attachment.Name); err != nil {
+			add(SeverityError, field, "%v", err)
+		}
+		if attachment.ContentType == "" {
+			add(SeverityError, field, "attachment ContentType required")
+		}
+		if err := attachment.readable(); err != nil {
+			add(SeverityError, field, "%v", err)
+		}
+	}
+
+	// The size can only be worked out for a message that
+	// would otherwise encode, and Readers of unknown length
+	// are instead checked as the message is sent
+	if len(problems.Errors()) != 0 {
+		return problems, nil
+	}
+
+	enc, err := m.encoder()
+	if err != nil {
+		add(SeverityError, "Message", "%v", err)
+		return problems, nil
+	}
+	if size, ok := enc.size(); ok && size > maxMessageSize {
+		add(SeverityError, "Attachments", "message + attachments cannot excede 10MB. Current size: %d Bytes", size)
+		return problems, nil
+	}
+
+	return problems, enc
+}
+
+// validateAddress checks that an address
+// can be parsed as a single email address
+func validateAddress(address EmailAddress) error {
+	parsed, err := mail.ParseAddress(address.Email)
+	if err != nil || parsed.Address != address.Email {
+		return fmt.Errorf("invalid email address %q", address.Email)
+	}
+
+	return nil
+}
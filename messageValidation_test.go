@@ -0,0 +1,46 @@
+package gostmark
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestValidateUnreadableAttachments(t *testing.T) {
+	consumed := NewAttachment("b.txt", "text/plain", unseekable{strings.NewReader("b")})
+	if err := consumed.writeContent(ioutil.Discard); err != nil {
+		t.Fatalf("writeContent: %v", err)
+	}
+
+	m := testMessage(
+		&Attachment{Name: "a.txt", ContentType: "text/plain"},
+		consumed,
+	)
+	problems := m.Validate().Errors()
+	if len(problems) != 2 {
+		t.Fatalf("Validate() = %v, want 2 errors", problems)
+	}
+	if problems[0].Field != "Attachments[0]" || problems[0].Message != errAttachmentNoReader.Error() {
+		t.Errorf("problems[0] = %+v", problems[0])
+	}
+	if problems[1].Field != "Attachments[1]" || problems[1].Message != errAttachmentConsumed.Error() {
+		t.Errorf("problems[1] = %+v", problems[1])
+	}
+
+	if _, err := json.Marshal(m); err == nil || !strings.Contains(err.Error(), problems.Error()) {
+		t.Errorf("Marshal error = %v, want %v", err, problems)
+	}
+}
+
+func TestValidateSeekableAttachmentAfterSend(t *testing.T) {
+	m := testMessage(NewAttachment("a.txt", "text/plain", strings.NewReader("a")))
+	for i := 0; i < 2; i++ {
+		if _, err := json.Marshal(m); err != nil {
+			t.Fatalf("Marshal %d: %v", i, err)
+		}
+		if problems := m.Validate(); len(problems) != 0 {
+			t.Fatalf("Validate() after Marshal %d = %v", i, problems)
+		}
+	}
+}
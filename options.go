@@ -0,0 +1,76 @@
+package gostmark
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/themartorana/Gostmark/v2/raw"
+)
+
+// RetryPolicy controls how the client retries transient
+// failures: connection errors before the request was
+// written, 429 Too Many Requests (honoring Retry-After)
+// and 503 Service Unavailable
+type RetryPolicy = raw.RetryPolicy
+
+// RetryAttempt is passed to RetryPolicy.OnAttempt
+// after every attempt at a request
+type RetryAttempt = raw.Attempt
+
+// DefaultRetryPolicy makes up to three attempts,
+// backing off from half a second
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+	}
+}
+
+// Option configures optional Client behavior. Options
+// are passed to the ClientFor... functions or Client.With
+type Option func(*Client)
+
+// WithHTTPClient sends every request through the supplied
+// http.Client, so proxies, custom RoundTrippers and
+// connection pooling can be controlled by the caller
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout bounds every request made by the client.
+// The timeout is applied on top of any deadline already
+// carried by the context passed to a ...Context method
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetryPolicy retries transient failures according
+// to policy. Clients do not retry unless configured to.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = &policy
+	}
+}
+
+// WithHost points the client at a different API host,
+// mostly useful for testing against a local server
+func WithHost(host string) Option {
+	return func(c *Client) {
+		c.Host = host
+	}
+}
+
+// With returns a copy of the client with the
+// supplied options applied
+func (c Client) With(opts ...Option) Client {
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
+}
@@ -1,11 +1,18 @@
 package raw
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"github.com/franela/goreq"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 type errorInfo struct {
@@ -13,165 +20,390 @@ type errorInfo struct {
 	Message   string
 }
 
-func ResponseFromPostmarkPost(host string, url string, headers map[string]string, body interface{}) (string, error) {
-	req := goreq.Request{
-		Method: "POST",
-		Uri: fmt.Sprintf(
-			"%s%s",
-			host,
-			url,
-		),
-		Accept:      "application/json",
-		ContentType: "application/json",
-	}
+// APIError is returned for every non-200 response from
+// Postmark. ErrorCode is Postmark's own error code, when
+// the response carried one, and Body is the raw response.
+// RetryAfter is set when the response asked the caller
+// to back off before trying again.
+type APIError struct {
+	StatusCode int
+	ErrorCode  int
+	Message    string
+	Body       string
+	RetryAfter time.Duration
+}
 
-	// Add headers
-	for key, val := range headers {
-		req.AddHeader(key, val)
+func (e *APIError) Error() string {
+	if e.ErrorCode != 0 {
+		return fmt.Sprintf(
+			"API error %d: %s",
+			e.ErrorCode,
+			e.Message,
+		)
 	}
 
-	// Body?
-	if body != nil {
-		req.Body = body
-		req.Method = "POST"
-	}
+	return fmt.Sprintf(
+		"HTTP %d: %s",
+		e.StatusCode,
+		e.Message,
+	)
+}
 
-	// Send
-	resp, err := req.Do()
-	if err != nil {
-		return "", err
+// newAPIError builds an APIError from a failed response,
+// falling back to a generic message for the status code
+// when Postmark did not return an error document
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Body:       string(body),
 	}
 
-	// Return body
-	respBody, err := resp.Body.ToString()
-	if err != nil {
-		return "", err
+	var errInfo errorInfo
+	if err := json.Unmarshal(body, &errInfo); err == nil {
+		apiErr.ErrorCode = errInfo.ErrorCode
+		apiErr.Message = errInfo.Message
+	}
+	if apiErr.Message != "" {
+		return apiErr
 	}
 
-	// Check response code
-	switch resp.StatusCode {
-	case 200:
-		return respBody, nil
+	switch statusCode {
 	case 401:
-		return "", errors.New("Missing or incorrect API token in header")
+		apiErr.Message = "Missing or incorrect API token in header"
 	case 422:
-		var errInfo errorInfo
-		err = json.Unmarshal([]byte(respBody), &errInfo)
-		if err == nil {
-			err = errors.New(
-				fmt.Sprintf(
-					"API error %d: %s",
-					errInfo.ErrorCode,
-					errInfo.Message,
-				),
-			)
-		} else {
-			var bodyString string
-			bodyString, err = resp.Body.ToString()
-			if err == nil {
-				err = errors.New(
-					fmt.Sprintf(
-						"API error %d: %s",
-						resp.StatusCode,
-						bodyString,
-					),
-				)
-			} else {
-				err = errors.New("422: Unprocessable error")
-			}
-		}
-		return "", err
+		apiErr.Message = "Unprocessable entity"
+	case 429:
+		apiErr.Message = "Too many requests"
 	case 500:
-		return "", errors.New("Internal Server Error")
+		apiErr.Message = "Internal Server Error"
 	case 503:
-		return "", errors.New("Postmark Servers Temporarilty Unavailable")
+		apiErr.Message = "Postmark Servers Temporarily Unavailable"
 	default:
-		return "", errors.New(
-			fmt.Sprintf(
-				"Unrecognized error %d: %s",
-				resp.StatusCode,
-				respBody,
-			),
-		)
+		apiErr.Message = "Unrecognized error"
+		if len(body) > 0 {
+			apiErr.Message = fmt.Sprintf("Unrecognized error: %s", body)
+		}
+	}
+
+	return apiErr
+}
+
+// Transport describes how requests reach Postmark:
+// the API host, the http.Client used to send them and
+// how transient failures are retried. A nil HTTPClient
+// falls back to http.DefaultClient, a nil Retry never
+// retries.
+type Transport struct {
+	Host       string
+	HTTPClient *http.Client
+	Retry      *RetryPolicy
+}
+
+func (t Transport) httpClient() *http.Client {
+	if t.HTTPClient != nil {
+		return t.HTTPClient
 	}
+
+	return http.DefaultClient
 }
 
+// BodyFunc produces a request body on demand. Unlike a
+// plain io.Reader it can be called again, so requests with
+// streamed bodies can still be retried. Only use it for
+// bodies that really can be produced more than once.
+type BodyFunc func() (io.ReadCloser, error)
+
+// Request describes a single call to the Postmark API.
+// Strings, byte slices, io.Readers and BodyFuncs in Body
+// are sent as-is, anything else is encoded as JSON.
+type Request struct {
+	Method  string
+	Path    string
+	Headers map[string]string
+	Query   url.Values
+	Body    interface{}
+}
+
+// Do sends r through t and decodes the JSON response into
+// out. out may be nil when the response is not needed.
+// Every non-200 response is returned as an *APIError.
+func Do(ctx context.Context, t Transport, r Request, out interface{}) error {
+	respBody, err := t.do(ctx, r)
+	if err != nil {
+		return err
+	}
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(respBody, out)
+}
+
+// Post sends body to the Postmark endpoint at url and
+// returns the response body
+func (t Transport) Post(ctx context.Context, url string, headers map[string]string, body interface{}) (string, error) {
+	respBody, err := t.do(ctx, Request{
+		Method:  http.MethodPost,
+		Path:    url,
+		Headers: headers,
+		Body:    body,
+	})
+	return string(respBody), err
+}
+
+// Get requests the Postmark endpoint at url with the
+// supplied querystring and returns the response body
+func (t Transport) Get(ctx context.Context, url string, headers map[string]string, querystring url.Values) (string, error) {
+	respBody, err := t.do(ctx, Request{
+		Method:  http.MethodGet,
+		Path:    url,
+		Headers: headers,
+		Query:   querystring,
+	})
+	return string(respBody), err
+}
+
+// ResponseFromPostmarkPost is Transport.Post with
+// http.DefaultClient and no cancellation
+func ResponseFromPostmarkPost(host string, url string, headers map[string]string, body interface{}) (string, error) {
+	return Transport{Host: host}.Post(context.Background(), url, headers, body)
+}
+
+// ResponseFromPostmarkGet is Transport.Get with
+// http.DefaultClient and no cancellation
 func ResponseFromPostmarkGet(host string, url string, headers map[string]string, querystring url.Values) (string, error) {
-	req := goreq.Request{
-		Method: "GET",
-		Uri: fmt.Sprintf(
+	return Transport{Host: host}.Get(context.Background(), url, headers, querystring)
+}
+
+func (t Transport) do(ctx context.Context, r Request) ([]byte, error) {
+	open, streamed := r.Body.(BodyFunc)
+
+	var body io.Reader
+	if !streamed {
+		var err error
+		body, err = bodyReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		r.Method,
+		fmt.Sprintf(
 			"%s%s",
-			host,
-			url,
+			t.Host,
+			r.Path,
 		),
-		Accept:      "application/json",
-		ContentType: "application/json",
+		body,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Streamed bodies are only opened once the request
+	// is built, so that nothing is left to close if
+	// building it fails
+	if streamed {
+		rc, err := open()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = rc
+		req.GetBody = open
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
 	// Add headers
-	for key, val := range headers {
-		req.AddHeader(key, val)
+	for key, val := range r.Headers {
+		req.Header.Set(key, val)
 	}
 
 	// Querystring
-	req.QueryString = querystring
+	if len(r.Query) > 0 {
+		req.URL.RawQuery = r.Query.Encode()
+	}
+
+	return t.send(req)
+}
+
+// bodyReader turns a request body into something
+// http.Request can send
+func bodyReader(body interface{}) (io.Reader, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return strings.NewReader(b), nil
+	case []byte:
+		return bytes.NewReader(b), nil
+	case io.Reader:
+		return b, nil
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(encoded), nil
+	}
+}
+
+// send performs req, retrying transient failures
+// according to the transport's RetryPolicy
+func (t Transport) send(req *http.Request) ([]byte, error) {
+	ctx := req.Context()
+	for n := 1; ; n++ {
+		out := t.sendOnce(req)
+
+		attempt := Attempt{
+			Number:     n,
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			StatusCode: out.statusCode,
+			Err:        out.err,
+		}
+		if out.retryable && t.Retry.shouldRetry(n) && rewindable(req) && ctx.Err() == nil {
+			attempt.Retrying = true
+			attempt.Wait = t.Retry.backoff(n, out.retryAfter)
+		}
+		t.Retry.observe(attempt)
+
+		if !attempt.Retrying {
+			return out.body, out.err
+		}
+
+		// Wait, unless the caller gives up first
+		timer := time.NewTimer(attempt.Wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, &waitError{ctxErr: ctx.Err(), last: out.err}
+		case <-timer.C:
+		}
+
+		var err error
+		req, err = rewind(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// outcome is the result of a single attempt
+type outcome struct {
+	body       []byte
+	statusCode int
+	err        error
+
+	retryable  bool
+	retryAfter time.Duration
+}
+
+func (t Transport) sendOnce(req *http.Request) outcome {
+	// Track whether the request made it onto the wire,
+	// since only unsent requests are safe to repeat
+	var wrote int32
+	trace := &httptrace.ClientTrace{
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			if info.Err == nil {
+				atomic.StoreInt32(&wrote, 1)
+			}
+		},
+	}
+	traced := req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	// A body that fails to produce its content will
+	// fail the same way again, so is not retried
+	var body *trackedBody
+	if req.Body != nil && req.Body != http.NoBody {
+		body = &trackedBody{ReadCloser: req.Body}
+		traced.Body = body
+	}
 
 	// Send
-	resp, err := req.Do()
+	resp, err := t.httpClient().Do(traced)
 	if err != nil {
-		return "", err
+		return outcome{
+			err:       err,
+			retryable: atomic.LoadInt32(&wrote) == 0 && !body.failed(),
+		}
 	}
+	defer resp.Body.Close()
 
 	// Return body
-	respBody, err := resp.Body.ToString()
+	b, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return outcome{
+			statusCode: resp.StatusCode,
+			err:        err,
+		}
 	}
 
 	// Check response code
-	switch resp.StatusCode {
-	case 200:
-		return respBody, nil
-	case 401:
-		return "", errors.New("Missing or incorrect API token in header")
-	case 422:
-		var errInfo errorInfo
-		err = json.Unmarshal([]byte(respBody), &errInfo)
-		if err == nil {
-			err = errors.New(
-				fmt.Sprintf(
-					"API error %d: %s",
-					errInfo.ErrorCode,
-					errInfo.Message,
-				),
-			)
-		} else {
-			var bodyString string
-			bodyString, err = resp.Body.ToString()
-			if err == nil {
-				err = errors.New(
-					fmt.Sprintf(
-						"API error %d: %s",
-						resp.StatusCode,
-						bodyString,
-					),
-				)
-			} else {
-				err = errors.New("422: Unprocessable error")
-			}
+	if resp.StatusCode == 200 {
+		return outcome{
+			body:       b,
+			statusCode: resp.StatusCode,
 		}
-		return "", err
-	case 500:
-		return "", errors.New("Internal Server Error")
-	case 503:
-		return "", errors.New("Postmark Servers Temporarilty Unavailable")
-	default:
-		return "", errors.New(
-			fmt.Sprintf(
-				"Unrecognized error %d: %s",
-				resp.StatusCode,
-				respBody,
-			),
-		)
 	}
+
+	apiErr := newAPIError(resp.StatusCode, b)
+	apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	return outcome{
+		statusCode: resp.StatusCode,
+		err:        apiErr,
+		retryable:  retryableStatus(resp.StatusCode),
+		retryAfter: apiErr.RetryAfter,
+	}
+}
+
+// trackedBody records whether reading a request body
+// returned an error before the body was closed
+type trackedBody struct {
+	io.ReadCloser
+	closed   int32
+	failures int32
+}
+
+func (tb *trackedBody) Read(p []byte) (int, error) {
+	n, err := tb.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && atomic.LoadInt32(&tb.closed) == 0 {
+		atomic.StoreInt32(&tb.failures, 1)
+	}
+
+	return n, err
+}
+
+func (tb *trackedBody) Close() error {
+	atomic.StoreInt32(&tb.closed, 1)
+	return tb.ReadCloser.Close()
+}
+
+// failed reports whether reading the body failed.
+// A nil body never fails.
+func (tb *trackedBody) failed() bool {
+	return tb != nil && atomic.LoadInt32(&tb.failures) != 0
+}
+
+// rewindable reports whether req can be sent again
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns a copy of req ready to be sent again
+func rewind(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		next.Body = body
+	}
+
+	return next, nil
 }
@@ -0,0 +1,25 @@
+package raw
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestBodyFuncNotOpenedForBadRequest(t *testing.T) {
+	opened := false
+	body := BodyFunc(func() (io.ReadCloser, error) {
+		opened = true
+		return ioutil.NopCloser(strings.NewReader("{}")), nil
+	})
+
+	tr := Transport{Host: "http://[::1"}
+	if _, err := tr.Post(context.Background(), "/email", nil, body); err == nil {
+		t.Fatal("expected an error for an invalid host")
+	}
+	if opened {
+		t.Error("body was opened for a request that could not be built")
+	}
+}
@@ -0,0 +1,149 @@
+package raw
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy controls how transient failures are retried.
+// Only failures that are safe to repeat are retried: connection
+// errors that happened before the request was written, 429
+// Too Many Requests and 503 Service Unavailable.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including
+	// the first. Values below 2 disable retries.
+	MaxAttempts int
+
+	// MinBackoff and MaxBackoff bound the exponential backoff
+	// between attempts. Zero values use 500ms and 30s. A
+	// server's Retry-After is also capped at MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// OnAttempt, if set, is called after every attempt
+	OnAttempt func(Attempt)
+}
+
+// Attempt describes a single try at a request
+type Attempt struct {
+	Number int
+	Method string
+	URL    string
+
+	// StatusCode is 0 when no response was received
+	StatusCode int
+	Err        error
+
+	// Retrying reports whether another attempt will be
+	// made, and Wait how long until it starts
+	Retrying bool
+	Wait     time.Duration
+}
+
+func (p *RetryPolicy) minBackoff() time.Duration {
+	if p.MinBackoff > 0 {
+		return p.MinBackoff
+	}
+
+	return defaultMinBackoff
+}
+
+func (p *RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff > 0 {
+		return p.MaxBackoff
+	}
+
+	return defaultMaxBackoff
+}
+
+// shouldRetry reports whether attempt number n may be
+// followed by another one. A nil policy never retries.
+func (p *RetryPolicy) shouldRetry(n int) bool {
+	return p != nil && n < p.MaxAttempts
+}
+
+// backoff returns the wait before the attempt following
+// attempt number n: exponential, capped, with jitter over
+// the upper half of the interval. A Retry-After supplied
+// by the server takes precedence, up to MaxBackoff.
+func (p *RetryPolicy) backoff(n int, retryAfter time.Duration) time.Duration {
+	max := p.maxBackoff()
+	if retryAfter > max {
+		return max
+	}
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	wait := p.minBackoff()
+	for i := 1; i < n && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func (p *RetryPolicy) observe(attempt Attempt) {
+	if p != nil && p.OnAttempt != nil {
+		p.OnAttempt(attempt)
+	}
+}
+
+// retryableStatus reports whether a response status
+// means the request was not acted upon
+func retryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests ||
+		statusCode == http.StatusServiceUnavailable
+}
+
+// parseRetryAfter reads a Retry-After header given
+// either in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
+
+// waitError is returned when the context ends while waiting
+// to retry. It unwraps to the error of the attempt that was
+// to be retried, such as an *APIError, and also matches the
+// context's error with errors.Is.
+type waitError struct {
+	ctxErr error
+	last   error
+}
+
+func (e *waitError) Error() string {
+	return fmt.Sprintf("%v while waiting to retry: %v", e.ctxErr, e.last)
+}
+
+func (e *waitError) Unwrap() error {
+	return e.last
+}
+
+func (e *waitError) Is(target error) bool {
+	return errors.Is(e.ctxErr, target)
+}
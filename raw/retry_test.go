@@ -0,0 +1,149 @@
+package raw
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetries retries quickly enough for tests
+func fastRetries(attempts int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: attempts,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	}
+}
+
+// failingServer answers the first failures requests with
+// status, then 200, recording every body it receives
+func failingServer(t *testing.T, failures int, status int) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var (
+		calls  int32
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		mu.Unlock()
+
+		if int(atomic.AddInt32(&calls, 1)) <= failures {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(status)
+			w.Write([]byte(`{"ErrorCode":0,"Message":"busy"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &bodies
+}
+
+func TestRetryTransientStatus(t *testing.T) {
+	for _, status := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		srv, bodies := failingServer(t, 2, status)
+
+		var attempts []Attempt
+		policy := fastRetries(3)
+		policy.OnAttempt = func(a Attempt) { attempts = append(attempts, a) }
+
+		tr := Transport{Host: srv.URL, Retry: policy}
+		body, err := tr.Post(context.Background(), "/email", nil, `{"a":1}`)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", status, err)
+		}
+		if body != `{"ok":true}` {
+			t.Errorf("%d: body = %q", status, body)
+		}
+		if len(*bodies) != 3 {
+			t.Fatalf("%d: server saw %d requests, want 3", status, len(*bodies))
+		}
+		for i, b := range *bodies {
+			if b != `{"a":1}` {
+				t.Errorf("%d: request %d body = %q", status, i, b)
+			}
+		}
+
+		// Retry-After is 1s but MaxBackoff caps it
+		if len(attempts) != 3 || !attempts[0].Retrying || attempts[0].Wait != policy.MaxBackoff {
+			t.Errorf("%d: attempts = %+v", status, attempts)
+		}
+	}
+}
+
+func TestRetryGivesUpWithAPIError(t *testing.T) {
+	srv, bodies := failingServer(t, 5, http.StatusServiceUnavailable)
+
+	tr := Transport{Host: srv.URL, Retry: fastRetries(2)}
+	_, err := tr.Get(context.Background(), "/server", nil, nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want a 503 *APIError", err)
+	}
+	if len(*bodies) != 2 {
+		t.Errorf("server saw %d requests, want 2", len(*bodies))
+	}
+}
+
+func TestRetryUnrewindableBody(t *testing.T) {
+	srv, bodies := failingServer(t, 1, http.StatusServiceUnavailable)
+
+	// A plain io.Reader cannot be sent again
+	tr := Transport{Host: srv.URL, Retry: fastRetries(3)}
+	_, err := tr.Post(context.Background(), "/email", nil, ioutil.NopCloser(strings.NewReader("x")))
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want an *APIError", err)
+	}
+	if len(*bodies) != 1 {
+		t.Errorf("server saw %d requests, want 1", len(*bodies))
+	}
+}
+
+func TestRetryWaitCancelled(t *testing.T) {
+	srv, _ := failingServer(t, 5, http.StatusTooManyRequests)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	tr := Transport{Host: srv.URL, Retry: &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Minute, MaxBackoff: time.Minute}}
+	_, err := tr.Get(ctx, "/server", nil, nil)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want it to match context.DeadlineExceeded", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("err = %v, want it to wrap the 429 *APIError", err)
+	}
+}
+
+func TestBackoffBounds(t *testing.T) {
+	p := &RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	for n := 1; n <= 10; n++ {
+		if wait := p.backoff(n, 0); wait < 50*time.Millisecond || wait > time.Second {
+			t.Errorf("backoff(%d) = %v, out of bounds", n, wait)
+		}
+	}
+	if wait := p.backoff(1, 300*time.Millisecond); wait != 300*time.Millisecond {
+		t.Errorf("Retry-After under MaxBackoff: got %v", wait)
+	}
+	if wait := p.backoff(1, time.Hour); wait != time.Second {
+		t.Errorf("Retry-After over MaxBackoff: got %v", wait)
+	}
+}
@@ -0,0 +1,134 @@
+package gostmark
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidReplyToken is returned when a mailbox hash
+// is not a reply token signed with the tracker's key
+var ErrInvalidReplyToken = errors.New("invalid reply token")
+
+const (
+	// replySignatureSize is how many bytes of the
+	// HMAC are kept in a token
+	replySignatureSize = 10
+
+	// maxLocalPartLength is the RFC 5321 limit on
+	// the part of an address before the "@"
+	maxLocalPartLength = 64
+)
+
+// replyEncoding is lower case so tokens survive mail
+// servers that fold the case of the local part
+var replyEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// ReplyTracker builds Reply-To addresses of the form
+// mailbox+<token>@domain, where the token carries an
+// entity ID of the caller's choosing signed with Key.
+// Postmark hands the token back as the MailboxHash of
+// the reply, so it can be routed without a lookup.
+type ReplyTracker struct {
+	// Mailbox and Domain form the inbound address,
+	// e.g. "inbound" and "replies.example.com"
+	Mailbox string
+	Domain  string
+
+	// Name is the display name of the Reply-To address
+	Name string
+
+	// Key signs tokens and must be kept secret
+	Key []byte
+}
+
+// Token returns the signed token for entityID
+func (rt ReplyTracker) Token(entityID string) (string, error) {
+	if len(rt.Key) == 0 {
+		return "", errors.New("ReplyTracker Key required")
+	}
+	if entityID == "" {
+		return "", errors.New("entity ID required")
+	}
+
+	return fmt.Sprintf(
+		"%s-%s",
+		replyEncoding.EncodeToString([]byte(entityID)),
+		replyEncoding.EncodeToString(rt.sign(entityID)),
+	), nil
+}
+
+// Address returns the Reply-To address for entityID
+func (rt ReplyTracker) Address(entityID string) (EmailAddress, error) {
+	if rt.Mailbox == "" || rt.Domain == "" {
+		return EmailAddress{}, errors.New("ReplyTracker Mailbox and Domain required")
+	}
+
+	token, err := rt.Token(entityID)
+	if err != nil {
+		return EmailAddress{}, err
+	}
+
+	localPart := fmt.Sprintf("%s+%s", rt.Mailbox, token)
+	if len(localPart) > maxLocalPartLength {
+		return EmailAddress{}, fmt.Errorf(
+			"entity ID %q is too long for a reply address",
+			entityID,
+		)
+	}
+
+	return EmailAddress{
+		Name:  rt.Name,
+		Email: fmt.Sprintf("%s@%s", localPart, rt.Domain),
+	}, nil
+}
+
+// SetReplyTo sets the message's ReplyTo to the
+// address for entityID
+func (rt ReplyTracker) SetReplyTo(m *Message, entityID string) error {
+	address, err := rt.Address(entityID)
+	if err != nil {
+		return err
+	}
+
+	m.Mutex.Lock()
+	m.ReplyTo = address
+	m.Mutex.Unlock()
+	return nil
+}
+
+// Verify checks the signature on a token, as found in
+// an inbound MailboxHash, and returns its entity ID
+func (rt ReplyTracker) Verify(mailboxHash string) (string, error) {
+	if len(rt.Key) == 0 {
+		return "", errors.New("ReplyTracker Key required")
+	}
+
+	parts := strings.Split(strings.ToLower(mailboxHash), "-")
+	if len(parts) != 2 {
+		return "", ErrInvalidReplyToken
+	}
+
+	entityID, err := replyEncoding.DecodeString(parts[0])
+	if err != nil || len(entityID) == 0 {
+		return "", ErrInvalidReplyToken
+	}
+	signature, err := replyEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrInvalidReplyToken
+	}
+	if !hmac.Equal(signature, rt.sign(string(entityID))) {
+		return "", ErrInvalidReplyToken
+	}
+
+	return string(entityID), nil
+}
+
+func (rt ReplyTracker) sign(entityID string) []byte {
+	mac := hmac.New(sha256.New, rt.Key)
+	mac.Write([]byte(entityID))
+	return mac.Sum(nil)[:replySignatureSize]
+}
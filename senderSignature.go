@@ -0,0 +1,183 @@
+package gostmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/themartorana/Gostmark/v2/raw"
+)
+
+// SenderSignature is a sender signature as
+// returned by ListSenderSignatures
+type SenderSignature struct {
+	ID                  int
+	Domain              string
+	EmailAddress        string
+	ReplyToEmailAddress string
+	Name                string
+	Confirmed           bool
+}
+
+type SenderSignatureDetails struct {
+	SenderSignature
+	DNSSettings
+
+	ConfirmationPersonalNote string
+}
+
+type SenderSignatureList struct {
+	TotalCount       int
+	SenderSignatures []SenderSignature
+}
+
+// SenderSignaturePacket creates or edits a sender
+// signature. FromEmail is only used on creation.
+type SenderSignaturePacket struct {
+	FromEmail                string `json:",omitempty"`
+	Name                     string `json:",omitempty"`
+	ReplyToEmail             string `json:",omitempty"`
+	ReturnPathDomain         string `json:",omitempty"`
+	ConfirmationPersonalNote string `json:",omitempty"`
+}
+
+func senderSignaturePath(signatureID int, action string) string {
+	return fmt.Sprintf(
+		"/senders/%d%s",
+		signatureID,
+		action,
+	)
+}
+
+// ListSenderSignatures lists the sender signatures on the account
+func (c Client) ListSenderSignatures(count, offset int) (SenderSignatureList, error) {
+	return c.ListSenderSignaturesContext(context.Background(), count, offset)
+}
+
+// ListSenderSignaturesContext is ListSenderSignatures
+// with a caller-supplied context
+func (c Client) ListSenderSignaturesContext(ctx context.Context, count, offset int) (SenderSignatureList, error) {
+	var ssl SenderSignatureList
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodGet,
+			Path:    "/senders",
+			Headers: c.accountHeaders(),
+			Query:   pageValues(count, offset),
+		},
+		&ssl,
+	)
+	if err != nil {
+		return SenderSignatureList{}, err
+	}
+
+	return ssl, nil
+}
+
+// GetSenderSignature retrieves a sender signature
+// along with its DNS settings
+func (c Client) GetSenderSignature(signatureID int) (SenderSignatureDetails, error) {
+	return c.GetSenderSignatureContext(context.Background(), signatureID)
+}
+
+// GetSenderSignatureContext is GetSenderSignature
+// with a caller-supplied context
+func (c Client) GetSenderSignatureContext(ctx context.Context, signatureID int) (SenderSignatureDetails, error) {
+	return c.senderSignatureRequest(ctx, http.MethodGet, senderSignaturePath(signatureID, ""), nil)
+}
+
+// CreateSenderSignature creates a sender signature and
+// sends a confirmation email to its FromEmail
+func (c Client) CreateSenderSignature(packet SenderSignaturePacket) (SenderSignatureDetails, error) {
+	return c.CreateSenderSignatureContext(context.Background(), packet)
+}
+
+// CreateSenderSignatureContext is CreateSenderSignature
+// with a caller-supplied context
+func (c Client) CreateSenderSignatureContext(ctx context.Context, packet SenderSignaturePacket) (SenderSignatureDetails, error) {
+	if packet.FromEmail == "" {
+		return SenderSignatureDetails{}, errors.New("FromEmail required")
+	}
+	if packet.Name == "" {
+		return SenderSignatureDetails{}, errors.New("Name required")
+	}
+
+	return c.senderSignatureRequest(ctx, http.MethodPost, "/senders", packet)
+}
+
+// EditSenderSignature updates a sender signature
+func (c Client) EditSenderSignature(signatureID int, packet SenderSignaturePacket) (SenderSignatureDetails, error) {
+	return c.EditSenderSignatureContext(context.Background(), signatureID, packet)
+}
+
+// EditSenderSignatureContext is EditSenderSignature
+// with a caller-supplied context
+func (c Client) EditSenderSignatureContext(ctx context.Context, signatureID int, packet SenderSignaturePacket) (SenderSignatureDetails, error) {
+	if packet.Name == "" {
+		return SenderSignatureDetails{}, errors.New("Name required")
+	}
+
+	// The sending address cannot be changed
+	packet.FromEmail = ""
+	return c.senderSignatureRequest(ctx, http.MethodPut, senderSignaturePath(signatureID, ""), packet)
+}
+
+// DeleteSenderSignature deletes a sender signature
+func (c Client) DeleteSenderSignature(signatureID int) error {
+	return c.DeleteSenderSignatureContext(context.Background(), signatureID)
+}
+
+// DeleteSenderSignatureContext is DeleteSenderSignature
+// with a caller-supplied context
+func (c Client) DeleteSenderSignatureContext(ctx context.Context, signatureID int) error {
+	return c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodDelete,
+			Path:    senderSignaturePath(signatureID, ""),
+			Headers: c.accountHeaders(),
+		},
+		nil,
+	)
+}
+
+// ResendSenderSignatureConfirmation sends the
+// confirmation email for a sender signature again
+func (c Client) ResendSenderSignatureConfirmation(signatureID int) error {
+	return c.ResendSenderSignatureConfirmationContext(context.Background(), signatureID)
+}
+
+// ResendSenderSignatureConfirmationContext is ResendSenderSignatureConfirmation
+// with a caller-supplied context
+func (c Client) ResendSenderSignatureConfirmationContext(ctx context.Context, signatureID int) error {
+	return c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodPost,
+			Path:    senderSignaturePath(signatureID, "/resend"),
+			Headers: c.accountHeaders(),
+		},
+		nil,
+	)
+}
+
+func (c Client) senderSignatureRequest(ctx context.Context, method, path string, body interface{}) (SenderSignatureDetails, error) {
+	var ssd SenderSignatureDetails
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  method,
+			Path:    path,
+			Headers: c.accountHeaders(),
+			Body:    body,
+		},
+		&ssd,
+	)
+	if err != nil {
+		return SenderSignatureDetails{}, err
+	}
+
+	return ssd, nil
+}
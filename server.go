@@ -1,8 +1,9 @@
 package gostmark
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
+	"net/http"
 
 	"fmt"
 
@@ -17,8 +18,13 @@ type Server struct {
 	ServerLink string
 	Color      string
 
-	SmtpApiActivated bool
-	RawEmailEnabled  bool
+	// DeliveryType can only be set when
+	// the server is created
+	DeliveryType DeliveryType
+
+	SmtpApiActivated        bool
+	RawEmailEnabled         bool
+	EnableSmtpApiErrorHooks bool
 
 	InboundAddress       string
 	InboundHookUrl       string
@@ -26,77 +32,117 @@ type Server struct {
 	InboundHash          string
 	InboundSpamThreshold int
 
-	BounceHookUrl string
-	OpenHookUrl   string
+	BounceHookUrl   string
+	OpenHookUrl     string
+	DeliveryHookUrl string
+	ClickHookUrl    string
+
+	IncludeBounceContentInHook bool
 
 	PostFirstOpenOnly bool
 	TrackOpens        bool
+	TrackLinks        LinkTracking
 
 	client Client
 }
 
+// maxInboundSpamThreshold is the highest spam
+// score Postmark accepts as a threshold
+const maxInboundSpamThreshold = 30
+
 func (s Server) Save() (Server, error) {
+	return s.SaveContext(context.Background())
+}
+
+// SaveContext is Save with a caller-supplied context
+func (s Server) SaveContext(ctx context.Context) (Server, error) {
 	if s.client.AccountToken == "" {
 		return s, errors.New("accountToken not provided. Please create new servers using Client.NewServer()")
 	}
 
-	var body string
+	// Parse the response back into a new server object,
+	// obstensibly with the things we submitted
+	sNew := Server{
+		client: s.client,
+	}
+
 	var err error
 	if s.ID != 0 {
-		body, err = s.saveEdit()
+		err = s.saveEdit(ctx, &sNew)
 	} else {
-		body, err = s.saveNew()
+		err = s.saveNew(ctx, &sNew)
 	}
 
 	if err != nil {
 		return s, err
 	}
 
-	// Parse the response back into a new server object,
-	// obstensibly with the things we submitted
-	sNew := Server{
-		client: s.client,
-	}
-	err = json.Unmarshal([]byte(body), &sNew)
-	return sNew, err
+	return sNew, nil
 }
 
-// Delete deletes the server from Postmark
-// TODO: Implement
+// Delete deletes the server from Postmark. Deletion
+// must be enabled for the account token in use.
 func (s Server) Delete() error {
-	return errors.New("NOT YET IMPLEMENTED")
+	return s.DeleteContext(context.Background())
 }
 
-func (s Server) saveEdit() (string, error) {
+// DeleteContext is Delete with a caller-supplied context
+func (s Server) DeleteContext(ctx context.Context) error {
+	if s.client.AccountToken == "" {
+		return errors.New("accountToken not provided. Please retrieve servers using a Client")
+	}
+	if s.ID == 0 {
+		return errors.New("cannot delete a server that has not been saved")
+	}
+
+	return s.client.do(
+		ctx,
+		raw.Request{
+			Method: http.MethodDelete,
+			Path: fmt.Sprintf(
+				"/servers/%d",
+				s.ID,
+			),
+			Headers: s.client.accountHeaders(),
+		},
+		nil,
+	)
+}
+
+func (s Server) saveEdit(ctx context.Context, out *Server) error {
 	savePacket, err := s.savePacket()
 	if err != nil {
-		return "", err
-	}
-	return raw.ResponseFromPostmarkPost(
-		s.client.Host,
-		fmt.Sprintf(
-			"/servers/%d",
-			s.ID,
-		),
-		map[string]string{
-			"X-Postmark-Account-Token": s.client.AccountToken,
+		return err
+	}
+	return s.client.do(
+		ctx,
+		raw.Request{
+			Method: http.MethodPut,
+			Path: fmt.Sprintf(
+				"/servers/%d",
+				s.ID,
+			),
+			Headers: s.client.accountHeaders(),
+			Body:    savePacket,
 		},
-		savePacket,
+		out,
 	)
 }
 
-func (s Server) saveNew() (string, error) {
+func (s Server) saveNew(ctx context.Context, out *Server) error {
 	savePacket, err := s.savePacket()
 	if err != nil {
-		return "", err
+		return err
 	}
-	return raw.ResponseFromPostmarkPost(
-		s.client.Host,
-		"/servers",
-		map[string]string{
-			"X-Postmark-Account-Token": s.client.AccountToken,
+	return s.client.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodPost,
+			Path:    "/servers",
+			Headers: s.client.accountHeaders(),
+			Body:    savePacket,
 		},
-		savePacket,
+		out,
 	)
 }
 
@@ -104,11 +150,20 @@ func (s Server) saveNew() (string, error) {
 // appropriate map for sending to the server
 func (s Server) savePacket() (map[string]interface{}, error) {
 	packet := map[string]interface{}{
-		"SmtpApiActivated":     s.SmtpApiActivated,
-		"RawEmailEnabled":      s.RawEmailEnabled,
-		"PostFirstOpenOnly":    s.PostFirstOpenOnly,
-		"TrackOpens":           s.TrackOpens,
-		"InboundSpamThreshold": s.InboundSpamThreshold,
+		"SmtpApiActivated":           s.SmtpApiActivated,
+		"RawEmailEnabled":            s.RawEmailEnabled,
+		"EnableSmtpApiErrorHooks":    s.EnableSmtpApiErrorHooks,
+		"IncludeBounceContentInHook": s.IncludeBounceContentInHook,
+		"PostFirstOpenOnly":          s.PostFirstOpenOnly,
+		"TrackOpens":                 s.TrackOpens,
+		"InboundSpamThreshold":       s.InboundSpamThreshold,
+	}
+
+	if s.InboundSpamThreshold < 0 || s.InboundSpamThreshold > maxInboundSpamThreshold {
+		return packet, fmt.Errorf(
+			"InboundSpamThreshold must be between 0 and %d",
+			maxInboundSpamThreshold,
+		)
 	}
 
 	// Name required
@@ -131,9 +186,31 @@ func (s Server) savePacket() (map[string]interface{}, error) {
 	if s.OpenHookUrl != "" {
 		packet["OpenHookUrl"] = s.OpenHookUrl
 	}
+	if s.DeliveryHookUrl != "" {
+		packet["DeliveryHookUrl"] = s.DeliveryHookUrl
+	}
+	if s.ClickHookUrl != "" {
+		packet["ClickHookUrl"] = s.ClickHookUrl
+	}
 	if s.InboundDomain != "" {
 		packet["InboundDomain"] = s.InboundDomain
 	}
+	if s.TrackLinks != "" {
+		if !s.TrackLinks.Valid() {
+			return packet, fmt.Errorf("invalid TrackLinks value %q", s.TrackLinks)
+		}
+		packet["TrackLinks"] = s.TrackLinks
+	}
+
+	// Delivery type is fixed once the server exists
+	if s.DeliveryType != "" && s.ID == 0 {
+		switch s.DeliveryType {
+		case DeliveryTypeLive, DeliveryTypeSandbox:
+			packet["DeliveryType"] = s.DeliveryType
+		default:
+			return packet, fmt.Errorf("invalid DeliveryType %q", s.DeliveryType)
+		}
+	}
 
 	return packet, nil
 }
@@ -0,0 +1,471 @@
+package gostmark
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/themartorana/Gostmark/v2/raw"
+)
+
+const statsDateFormat = "2006-01-02"
+
+// StatsFilter narrows every outbound statistics report
+type StatsFilter struct {
+	Tag           string
+	FromDate      time.Time
+	ToDate        time.Time
+	MessageStream string
+}
+
+// AsValues returns the filter as url.Values
+func (sf StatsFilter) AsValues() url.Values {
+	vals := make(url.Values)
+
+	if sf.Tag != "" {
+		vals.Add("tag", sf.Tag)
+	}
+	if !sf.FromDate.IsZero() {
+		vals.Add("fromdate", sf.FromDate.Format(statsDateFormat))
+	}
+	if !sf.ToDate.IsZero() {
+		vals.Add("todate", sf.ToDate.Format(statsDateFormat))
+	}
+	if sf.MessageStream != "" {
+		vals.Add("messagestream", sf.MessageStream)
+	}
+
+	return vals
+}
+
+// StatsDate is the calendar day of an
+// entry in a per-day statistics series
+type StatsDate struct {
+	time.Time
+}
+
+func (sd StatsDate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sd.Format(statsDateFormat))
+}
+
+func (sd *StatsDate) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	t, err := time.Parse(statsDateFormat, s)
+	if err != nil {
+		return err
+	}
+
+	sd.Time = t
+	return nil
+}
+
+// OutboundOverview is the summary of outbound traffic
+type OutboundOverview struct {
+	Sent                  int
+	Bounced               int
+	SMTPApiErrors         int
+	BounceRate            float64
+	SpamComplaints        int
+	SpamComplaintsRate    float64
+	Opens                 int
+	UniqueOpens           int
+	Tracked               int
+	WithClientRecorded    int
+	WithPlatformRecorded  int
+	WithReadTimeRecorded  int
+	TotalClicks           int
+	UniqueLinksClicked    int
+	TotalTrackedLinksSent int
+	WithLinkTracking      int
+	WithOpenTracking      int
+}
+
+type SentCounts struct {
+	Days []SentDay
+	Sent int
+}
+
+type SentDay struct {
+	Date StatsDate
+	Sent int
+}
+
+type BounceCounts struct {
+	Days         []BounceDay
+	HardBounce   int
+	SMTPApiError int
+	SoftBounce   int
+	Transient    int
+}
+
+type BounceDay struct {
+	Date         StatsDate
+	HardBounce   int
+	SMTPApiError int
+	SoftBounce   int
+	Transient    int
+}
+
+type SpamComplaintCounts struct {
+	Days          []SpamComplaintDay
+	SpamComplaint int
+}
+
+type SpamComplaintDay struct {
+	Date          StatsDate
+	SpamComplaint int
+}
+
+type TrackedEmailCounts struct {
+	Days    []TrackedEmailDay
+	Tracked int
+}
+
+type TrackedEmailDay struct {
+	Date    StatsDate
+	Tracked int
+}
+
+type OpenCounts struct {
+	Days   []OpenDay
+	Opens  int
+	Unique int
+}
+
+type OpenDay struct {
+	Date   StatsDate
+	Opens  int
+	Unique int
+}
+
+type ClickCounts struct {
+	Days   []ClickDay
+	Clicks int
+	Unique int
+}
+
+type ClickDay struct {
+	Date   StatsDate
+	Clicks int
+	Unique int
+}
+
+// PlatformCounts breaks opens or clicks down by
+// the kind of device they were recorded on
+type PlatformCounts struct {
+	Days    []PlatformDay
+	Desktop int
+	Mobile  int
+	Unknown int
+	WebMail int
+}
+
+type PlatformDay struct {
+	Date    StatsDate
+	Desktop int
+	Mobile  int
+	Unknown int
+	WebMail int
+}
+
+// ClickLocationCounts breaks clicks down by
+// the message body the link was in
+type ClickLocationCounts struct {
+	Days []ClickLocationDay
+	HTML int
+	Text int
+}
+
+type ClickLocationDay struct {
+	Date StatsDate
+	HTML int
+	Text int
+}
+
+// NamedCounts is a report keyed by names Postmark
+// chooses, such as email clients or browser families
+type NamedCounts struct {
+	Days   []NamedCountsDay
+	Totals map[string]int
+}
+
+type NamedCountsDay struct {
+	Date   StatsDate
+	Counts map[string]int
+}
+
+func (nc *NamedCounts) UnmarshalJSON(b []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+
+	nc.Totals = make(map[string]int, len(fields))
+	for name, value := range fields {
+		if name == "Days" {
+			if err := json.Unmarshal(value, &nc.Days); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var count int
+		if err := json.Unmarshal(value, &count); err != nil {
+			return err
+		}
+		nc.Totals[name] = count
+	}
+
+	return nil
+}
+
+func (ncd *NamedCountsDay) UnmarshalJSON(b []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+
+	ncd.Counts = make(map[string]int, len(fields))
+	for name, value := range fields {
+		if name == "Date" {
+			if err := json.Unmarshal(value, &ncd.Date); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var count int
+		if err := json.Unmarshal(value, &count); err != nil {
+			return err
+		}
+		ncd.Counts[name] = count
+	}
+
+	return nil
+}
+
+// getStats requests an outbound statistics report
+func (c Client) getStats(ctx context.Context, path string, filter StatsFilter, out interface{}) error {
+	return c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodGet,
+			Path:    "/stats/outbound" + path,
+			Headers: c.serverHeaders(),
+			Query:   filter.AsValues(),
+		},
+		out,
+	)
+}
+
+// GetOutboundOverview returns the summary of outbound traffic
+func (c Client) GetOutboundOverview(filter StatsFilter) (OutboundOverview, error) {
+	return c.GetOutboundOverviewContext(context.Background(), filter)
+}
+
+// GetOutboundOverviewContext is GetOutboundOverview
+// with a caller-supplied context
+func (c Client) GetOutboundOverviewContext(ctx context.Context, filter StatsFilter) (OutboundOverview, error) {
+	var report OutboundOverview
+	err := c.getStats(ctx, "", filter, &report)
+	if err != nil {
+		return OutboundOverview{}, err
+	}
+
+	return report, nil
+}
+
+// GetSentCounts returns the number of messages sent per day
+func (c Client) GetSentCounts(filter StatsFilter) (SentCounts, error) {
+	return c.GetSentCountsContext(context.Background(), filter)
+}
+
+// GetSentCountsContext is GetSentCounts
+// with a caller-supplied context
+func (c Client) GetSentCountsContext(ctx context.Context, filter StatsFilter) (SentCounts, error) {
+	var report SentCounts
+	err := c.getStats(ctx, "/sends", filter, &report)
+	if err != nil {
+		return SentCounts{}, err
+	}
+
+	return report, nil
+}
+
+// GetBounceCounts returns bounces per day by type
+func (c Client) GetBounceCounts(filter StatsFilter) (BounceCounts, error) {
+	return c.GetBounceCountsContext(context.Background(), filter)
+}
+
+// GetBounceCountsContext is GetBounceCounts
+// with a caller-supplied context
+func (c Client) GetBounceCountsContext(ctx context.Context, filter StatsFilter) (BounceCounts, error) {
+	var report BounceCounts
+	err := c.getStats(ctx, "/bounce", filter, &report)
+	if err != nil {
+		return BounceCounts{}, err
+	}
+
+	return report, nil
+}
+
+// GetSpamComplaintCounts returns spam complaints per day
+func (c Client) GetSpamComplaintCounts(filter StatsFilter) (SpamComplaintCounts, error) {
+	return c.GetSpamComplaintCountsContext(context.Background(), filter)
+}
+
+// GetSpamComplaintCountsContext is GetSpamComplaintCounts
+// with a caller-supplied context
+func (c Client) GetSpamComplaintCountsContext(ctx context.Context, filter StatsFilter) (SpamComplaintCounts, error) {
+	var report SpamComplaintCounts
+	err := c.getStats(ctx, "/spam", filter, &report)
+	if err != nil {
+		return SpamComplaintCounts{}, err
+	}
+
+	return report, nil
+}
+
+// GetTrackedEmailCounts returns the number of messages
+// sent with open tracking enabled per day
+func (c Client) GetTrackedEmailCounts(filter StatsFilter) (TrackedEmailCounts, error) {
+	return c.GetTrackedEmailCountsContext(context.Background(), filter)
+}
+
+// GetTrackedEmailCountsContext is GetTrackedEmailCounts
+// with a caller-supplied context
+func (c Client) GetTrackedEmailCountsContext(ctx context.Context, filter StatsFilter) (TrackedEmailCounts, error) {
+	var report TrackedEmailCounts
+	err := c.getStats(ctx, "/tracked", filter, &report)
+	if err != nil {
+		return TrackedEmailCounts{}, err
+	}
+
+	return report, nil
+}
+
+// GetOpenCounts returns total and unique opens per day
+func (c Client) GetOpenCounts(filter StatsFilter) (OpenCounts, error) {
+	return c.GetOpenCountsContext(context.Background(), filter)
+}
+
+// GetOpenCountsContext is GetOpenCounts
+// with a caller-supplied context
+func (c Client) GetOpenCountsContext(ctx context.Context, filter StatsFilter) (OpenCounts, error) {
+	var report OpenCounts
+	err := c.getStats(ctx, "/opens", filter, &report)
+	if err != nil {
+		return OpenCounts{}, err
+	}
+
+	return report, nil
+}
+
+// GetOpenPlatformCounts returns opens per day by platform
+func (c Client) GetOpenPlatformCounts(filter StatsFilter) (PlatformCounts, error) {
+	return c.GetOpenPlatformCountsContext(context.Background(), filter)
+}
+
+// GetOpenPlatformCountsContext is GetOpenPlatformCounts
+// with a caller-supplied context
+func (c Client) GetOpenPlatformCountsContext(ctx context.Context, filter StatsFilter) (PlatformCounts, error) {
+	var report PlatformCounts
+	err := c.getStats(ctx, "/opens/platforms", filter, &report)
+	if err != nil {
+		return PlatformCounts{}, err
+	}
+
+	return report, nil
+}
+
+// GetOpenEmailClientCounts returns opens per day by email client
+func (c Client) GetOpenEmailClientCounts(filter StatsFilter) (NamedCounts, error) {
+	return c.GetOpenEmailClientCountsContext(context.Background(), filter)
+}
+
+// GetOpenEmailClientCountsContext is GetOpenEmailClientCounts
+// with a caller-supplied context
+func (c Client) GetOpenEmailClientCountsContext(ctx context.Context, filter StatsFilter) (NamedCounts, error) {
+	var report NamedCounts
+	err := c.getStats(ctx, "/opens/emailclients", filter, &report)
+	if err != nil {
+		return NamedCounts{}, err
+	}
+
+	return report, nil
+}
+
+// GetClickCounts returns total and unique clicks per day
+func (c Client) GetClickCounts(filter StatsFilter) (ClickCounts, error) {
+	return c.GetClickCountsContext(context.Background(), filter)
+}
+
+// GetClickCountsContext is GetClickCounts
+// with a caller-supplied context
+func (c Client) GetClickCountsContext(ctx context.Context, filter StatsFilter) (ClickCounts, error) {
+	var report ClickCounts
+	err := c.getStats(ctx, "/clicks", filter, &report)
+	if err != nil {
+		return ClickCounts{}, err
+	}
+
+	return report, nil
+}
+
+// GetClickBrowserFamilyCounts returns clicks per day by browser
+func (c Client) GetClickBrowserFamilyCounts(filter StatsFilter) (NamedCounts, error) {
+	return c.GetClickBrowserFamilyCountsContext(context.Background(), filter)
+}
+
+// GetClickBrowserFamilyCountsContext is GetClickBrowserFamilyCounts
+// with a caller-supplied context
+func (c Client) GetClickBrowserFamilyCountsContext(ctx context.Context, filter StatsFilter) (NamedCounts, error) {
+	var report NamedCounts
+	err := c.getStats(ctx, "/clicks/browserfamilies", filter, &report)
+	if err != nil {
+		return NamedCounts{}, err
+	}
+
+	return report, nil
+}
+
+// GetClickPlatformCounts returns clicks per day by platform
+func (c Client) GetClickPlatformCounts(filter StatsFilter) (PlatformCounts, error) {
+	return c.GetClickPlatformCountsContext(context.Background(), filter)
+}
+
+// GetClickPlatformCountsContext is GetClickPlatformCounts
+// with a caller-supplied context
+func (c Client) GetClickPlatformCountsContext(ctx context.Context, filter StatsFilter) (PlatformCounts, error) {
+	var report PlatformCounts
+	err := c.getStats(ctx, "/clicks/platforms", filter, &report)
+	if err != nil {
+		return PlatformCounts{}, err
+	}
+
+	return report, nil
+}
+
+// GetClickLocationCounts returns clicks per day
+// by the body, HTML or text, they came from
+func (c Client) GetClickLocationCounts(filter StatsFilter) (ClickLocationCounts, error) {
+	return c.GetClickLocationCountsContext(context.Background(), filter)
+}
+
+// GetClickLocationCountsContext is GetClickLocationCounts
+// with a caller-supplied context
+func (c Client) GetClickLocationCountsContext(ctx context.Context, filter StatsFilter) (ClickLocationCounts, error) {
+	var report ClickLocationCounts
+	err := c.getStats(ctx, "/clicks/location", filter, &report)
+	if err != nil {
+		return ClickLocationCounts{}, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,190 @@
+package gostmark
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/themartorana/Gostmark/v2/raw"
+)
+
+// SuppressionReason is why an address was suppressed
+type SuppressionReason string
+
+const (
+	SuppressionReasonHardBounce        SuppressionReason = "HardBounce"
+	SuppressionReasonSpamComplaint     SuppressionReason = "SpamComplaint"
+	SuppressionReasonManualSuppression SuppressionReason = "ManualSuppression"
+)
+
+// SuppressionOrigin is who suppressed an address
+type SuppressionOrigin string
+
+const (
+	SuppressionOriginRecipient SuppressionOrigin = "Recipient"
+	SuppressionOriginCustomer  SuppressionOrigin = "Customer"
+	SuppressionOriginAdmin     SuppressionOrigin = "Admin"
+)
+
+// Statuses reported per address by
+// CreateSuppressions and DeleteSuppressions
+const (
+	SuppressionStatusSuppressed = "Suppressed"
+	SuppressionStatusDeleted    = "Deleted"
+	SuppressionStatusFailed     = "Failed"
+)
+
+// maxSuppressionsPerRequest is how many addresses Postmark
+// accepts in one create or delete request
+const maxSuppressionsPerRequest = 50
+
+type Suppression struct {
+	EmailAddress      string
+	SuppressionReason SuppressionReason
+	Origin            SuppressionOrigin
+	CreatedAt         time.Time
+}
+
+// SuppressionResult is the outcome for a single address
+// of a bulk create or delete. Message explains failures.
+type SuppressionResult struct {
+	EmailAddress string
+	Status       string
+	Message      string
+}
+
+// SuppressionSearchPacket filters GetSuppressions
+type SuppressionSearchPacket struct {
+	SuppressionReason SuppressionReason
+	Origin            SuppressionOrigin
+	FromDate          time.Time
+	ToDate            time.Time
+	EmailAddress      string
+}
+
+// AsValues returns the search packet as url.Values
+func (ssp SuppressionSearchPacket) AsValues() url.Values {
+	vals := make(url.Values)
+
+	if ssp.SuppressionReason != "" {
+		vals.Add("SuppressionReason", string(ssp.SuppressionReason))
+	}
+	if ssp.Origin != "" {
+		vals.Add("Origin", string(ssp.Origin))
+	}
+	if !ssp.FromDate.IsZero() {
+		vals.Add("fromdate", ssp.FromDate.Format(statsDateFormat))
+	}
+	if !ssp.ToDate.IsZero() {
+		vals.Add("todate", ssp.ToDate.Format(statsDateFormat))
+	}
+	if ssp.EmailAddress != "" {
+		vals.Add("EmailAddress", ssp.EmailAddress)
+	}
+
+	return vals
+}
+
+func suppressionsPath(streamID string, action string) string {
+	return messageStreamPath(streamID, "/suppressions"+action)
+}
+
+// GetSuppressions dumps the suppressed addresses of a stream
+func (c Client) GetSuppressions(streamID string, packet SuppressionSearchPacket) ([]Suppression, error) {
+	return c.GetSuppressionsContext(context.Background(), streamID, packet)
+}
+
+// GetSuppressionsContext is GetSuppressions
+// with a caller-supplied context
+func (c Client) GetSuppressionsContext(ctx context.Context, streamID string, packet SuppressionSearchPacket) ([]Suppression, error) {
+	var dump struct {
+		Suppressions []Suppression
+	}
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodGet,
+			Path:    suppressionsPath(streamID, "/dump"),
+			Headers: c.serverHeaders(),
+			Query:   packet.AsValues(),
+		},
+		&dump,
+	)
+	if err != nil {
+		return []Suppression{}, err
+	}
+
+	return dump.Suppressions, nil
+}
+
+// CreateSuppressions suppresses the addresses on a stream.
+// Large lists are sent in batches of 50.
+func (c Client) CreateSuppressions(streamID string, emailAddresses []string) ([]SuppressionResult, error) {
+	return c.CreateSuppressionsContext(context.Background(), streamID, emailAddresses)
+}
+
+// CreateSuppressionsContext is CreateSuppressions
+// with a caller-supplied context
+func (c Client) CreateSuppressionsContext(ctx context.Context, streamID string, emailAddresses []string) ([]SuppressionResult, error) {
+	return c.changeSuppressions(ctx, suppressionsPath(streamID, ""), emailAddresses)
+}
+
+// DeleteSuppressions reactivates the addresses on a stream.
+// Addresses suppressed by spam complaints cannot be deleted.
+func (c Client) DeleteSuppressions(streamID string, emailAddresses []string) ([]SuppressionResult, error) {
+	return c.DeleteSuppressionsContext(context.Background(), streamID, emailAddresses)
+}
+
+// DeleteSuppressionsContext is DeleteSuppressions
+// with a caller-supplied context
+func (c Client) DeleteSuppressionsContext(ctx context.Context, streamID string, emailAddresses []string) ([]SuppressionResult, error) {
+	return c.changeSuppressions(ctx, suppressionsPath(streamID, "/delete"), emailAddresses)
+}
+
+// changeSuppressions posts the addresses to path in
+// batches and collects the per-address results. If a
+// batch fails, the results of earlier ones are returned
+// along with the error.
+func (c Client) changeSuppressions(ctx context.Context, path string, emailAddresses []string) ([]SuppressionResult, error) {
+	results := make([]SuppressionResult, 0, len(emailAddresses))
+	for start := 0; start < len(emailAddresses); start += maxSuppressionsPerRequest {
+		end := start + maxSuppressionsPerRequest
+		if end > len(emailAddresses) {
+			end = len(emailAddresses)
+		}
+
+		type suppressionEntry struct {
+			EmailAddress string
+		}
+		packet := struct {
+			Suppressions []suppressionEntry
+		}{
+			Suppressions: make([]suppressionEntry, 0, end-start),
+		}
+		for _, emailAddress := range emailAddresses[start:end] {
+			packet.Suppressions = append(packet.Suppressions, suppressionEntry{emailAddress})
+		}
+
+		var response struct {
+			Suppressions []SuppressionResult
+		}
+		err := c.do(
+			ctx,
+			raw.Request{
+				Method:  http.MethodPost,
+				Path:    path,
+				Headers: c.serverHeaders(),
+				Body:    packet,
+			},
+			&response,
+		)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, response.Suppressions...)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,317 @@
+package gostmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/themartorana/Gostmark/v2/raw"
+)
+
+type Template struct {
+	TemplateId         int
+	Name               string
+	Alias              string
+	Subject            string
+	HtmlBody           string
+	TextBody           string
+	AssociatedServerId int
+	Active             bool
+	TemplateType       TemplateType
+
+	// LayoutTemplate is the alias of the layout a
+	// standard template is rendered inside of
+	LayoutTemplate string
+}
+
+// TemplateSummary is a template as returned by
+// ListTemplates, without its content
+type TemplateSummary struct {
+	TemplateId     int
+	Name           string
+	Alias          string
+	Active         bool
+	TemplateType   TemplateType
+	LayoutTemplate string
+}
+
+type TemplateList struct {
+	TotalCount int
+	Templates  []TemplateSummary
+}
+
+// TemplateSearchPacket filters ListTemplates
+type TemplateSearchPacket struct {
+	TemplateType   TemplateType
+	LayoutTemplate string
+
+	Count  int
+	Offset int
+}
+
+// TemplateValidationPacket is the content to be validated
+// and the model used for the test render
+type TemplateValidationPacket struct {
+	Subject                    string       `json:",omitempty"`
+	HtmlBody                   string       `json:",omitempty"`
+	TextBody                   string       `json:",omitempty"`
+	TestRenderModel            interface{}  `json:",omitempty"`
+	InlineCssForHtmlTestRender bool         `json:",omitempty"`
+	TemplateType               TemplateType `json:",omitempty"`
+	LayoutTemplate             string       `json:",omitempty"`
+}
+
+type TemplateValidation struct {
+	AllContentIsValid      bool
+	Subject                TemplateContentValidation
+	HtmlBody               TemplateContentValidation
+	TextBody               TemplateContentValidation
+	SuggestedTemplateModel map[string]interface{}
+}
+
+// TemplateContentValidation is the result of
+// validating and test-rendering one field
+type TemplateContentValidation struct {
+	ContentIsValid   bool
+	ValidationErrors []TemplateValidationError
+	RenderedContent  string
+}
+
+type TemplateValidationError struct {
+	Message           string
+	Line              int
+	CharacterPosition int
+}
+
+// AsValues returns the search packet as url.Values
+func (tsp TemplateSearchPacket) AsValues() url.Values {
+	vals := make(url.Values)
+	vals.Add("offset", strconv.Itoa(tsp.Offset))
+
+	if tsp.Count == 0 {
+		tsp.Count = 500
+	}
+	vals.Add("count", strconv.Itoa(tsp.Count))
+
+	if tsp.TemplateType != "" {
+		vals.Add("TemplateType", string(tsp.TemplateType))
+	}
+	if tsp.LayoutTemplate != "" {
+		vals.Add("LayoutTemplate", tsp.LayoutTemplate)
+	}
+
+	return vals
+}
+
+// savePacket creates an appropriate map for sending
+// to the server. TemplateType can only be set when the
+// template is created.
+func (t Template) savePacket(create bool) (map[string]interface{}, error) {
+	packet := map[string]interface{}{}
+
+	if create && t.Name == "" {
+		return packet, errors.New("Template name required")
+	}
+	if t.Name != "" {
+		packet["Name"] = t.Name
+	}
+	if t.Alias != "" {
+		packet["Alias"] = t.Alias
+	}
+	if t.Subject != "" {
+		packet["Subject"] = t.Subject
+	}
+	if t.HtmlBody != "" {
+		packet["HtmlBody"] = t.HtmlBody
+	}
+	if t.TextBody != "" {
+		packet["TextBody"] = t.TextBody
+	}
+	if t.LayoutTemplate != "" {
+		packet["LayoutTemplate"] = t.LayoutTemplate
+	}
+	if create && t.TemplateType != "" {
+		packet["TemplateType"] = t.TemplateType
+	}
+
+	return packet, nil
+}
+
+// templatePath returns the API path for a
+// template referenced by ID or alias
+func templatePath(idOrAlias string) string {
+	return fmt.Sprintf(
+		"/templates/%s",
+		url.PathEscape(idOrAlias),
+	)
+}
+
+// ListTemplates lists the templates on the server
+func (c Client) ListTemplates(packet TemplateSearchPacket) (TemplateList, error) {
+	return c.ListTemplatesContext(context.Background(), packet)
+}
+
+// ListTemplatesContext is ListTemplates
+// with a caller-supplied context
+func (c Client) ListTemplatesContext(ctx context.Context, packet TemplateSearchPacket) (TemplateList, error) {
+	var tl TemplateList
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodGet,
+			Path:    "/templates",
+			Headers: c.serverHeaders(),
+			Query:   packet.AsValues(),
+		},
+		&tl,
+	)
+	if err != nil {
+		return TemplateList{}, err
+	}
+
+	return tl, nil
+}
+
+// GetTemplate retrieves a template by its ID or alias
+func (c Client) GetTemplate(idOrAlias string) (Template, error) {
+	return c.GetTemplateContext(context.Background(), idOrAlias)
+}
+
+// GetTemplateContext is GetTemplate
+// with a caller-supplied context
+func (c Client) GetTemplateContext(ctx context.Context, idOrAlias string) (Template, error) {
+	var t Template
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodGet,
+			Path:    templatePath(idOrAlias),
+			Headers: c.serverHeaders(),
+		},
+		&t,
+	)
+	if err != nil {
+		return Template{}, err
+	}
+
+	return t, nil
+}
+
+// CreateTemplate creates a new template and returns
+// it with the ID Postmark assigned
+func (c Client) CreateTemplate(template Template) (Template, error) {
+	return c.CreateTemplateContext(context.Background(), template)
+}
+
+// CreateTemplateContext is CreateTemplate
+// with a caller-supplied context
+func (c Client) CreateTemplateContext(ctx context.Context, template Template) (Template, error) {
+	packet, err := template.savePacket(true)
+	if err != nil {
+		return template, err
+	}
+
+	// The response only echoes the template's
+	// identity, so decode over what was sent
+	created := template
+	err = c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodPost,
+			Path:    "/templates",
+			Headers: c.serverHeaders(),
+			Body:    packet,
+		},
+		&created,
+	)
+	if err != nil {
+		return template, err
+	}
+
+	return created, nil
+}
+
+// EditTemplate updates the template with the given ID or
+// alias. Only the non-empty fields of template are sent.
+func (c Client) EditTemplate(idOrAlias string, template Template) (Template, error) {
+	return c.EditTemplateContext(context.Background(), idOrAlias, template)
+}
+
+// EditTemplateContext is EditTemplate
+// with a caller-supplied context
+func (c Client) EditTemplateContext(ctx context.Context, idOrAlias string, template Template) (Template, error) {
+	packet, err := template.savePacket(false)
+	if err != nil {
+		return template, err
+	}
+
+	edited := template
+	err = c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodPut,
+			Path:    templatePath(idOrAlias),
+			Headers: c.serverHeaders(),
+			Body:    packet,
+		},
+		&edited,
+	)
+	if err != nil {
+		return template, err
+	}
+
+	return edited, nil
+}
+
+// DeleteTemplate deletes the template with the given ID or alias
+func (c Client) DeleteTemplate(idOrAlias string) error {
+	return c.DeleteTemplateContext(context.Background(), idOrAlias)
+}
+
+// DeleteTemplateContext is DeleteTemplate
+// with a caller-supplied context
+func (c Client) DeleteTemplateContext(ctx context.Context, idOrAlias string) error {
+	return c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodDelete,
+			Path:    templatePath(idOrAlias),
+			Headers: c.serverHeaders(),
+		},
+		nil,
+	)
+}
+
+// ValidateTemplate checks template content for errors and
+// renders it against the packet's TestRenderModel
+func (c Client) ValidateTemplate(packet TemplateValidationPacket) (TemplateValidation, error) {
+	return c.ValidateTemplateContext(context.Background(), packet)
+}
+
+// ValidateTemplateContext is ValidateTemplate
+// with a caller-supplied context
+func (c Client) ValidateTemplateContext(ctx context.Context, packet TemplateValidationPacket) (TemplateValidation, error) {
+	if packet.Subject == "" && packet.HtmlBody == "" && packet.TextBody == "" {
+		return TemplateValidation{}, errors.New("Subject, HtmlBody and TextBody cannot all be blank")
+	}
+
+	var tv TemplateValidation
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodPost,
+			Path:    "/templates/validate",
+			Headers: c.serverHeaders(),
+			Body:    packet,
+		},
+		&tv,
+	)
+	if err != nil {
+		return TemplateValidation{}, err
+	}
+
+	return tv, nil
+}
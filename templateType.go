@@ -0,0 +1,10 @@
+package gostmark
+
+// TemplateType distinguishes content templates
+// from the layouts they can be wrapped in
+type TemplateType string
+
+const (
+	TemplateTypeStandard TemplateType = "Standard"
+	TemplateTypeLayout   TemplateType = "Layout"
+)
@@ -0,0 +1,177 @@
+package gostmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/themartorana/Gostmark/v2/raw"
+)
+
+// Webhook is a webhook configured on a message stream.
+// It replaces the per-server hook URLs on Server.
+type Webhook struct {
+	ID            int              `json:",omitempty"`
+	Url           string           `json:",omitempty"`
+	MessageStream string           `json:",omitempty"`
+	HttpAuth      *WebhookHttpAuth `json:",omitempty"`
+	HttpHeaders   []Header         `json:",omitempty"`
+	Triggers      WebhookTriggers
+}
+
+// WebhookHttpAuth are the basic auth
+// credentials Postmark posts with
+type WebhookHttpAuth struct {
+	Username string
+	Password string
+}
+
+// WebhookTriggers selects which events
+// are posted to the webhook
+type WebhookTriggers struct {
+	Open               OpenWebhookTrigger
+	Click              WebhookTrigger
+	Delivery           WebhookTrigger
+	Bounce             ContentWebhookTrigger
+	SpamComplaint      ContentWebhookTrigger
+	SubscriptionChange WebhookTrigger
+}
+
+type WebhookTrigger struct {
+	Enabled bool
+}
+
+type OpenWebhookTrigger struct {
+	Enabled           bool
+	PostFirstOpenOnly bool
+}
+
+// ContentWebhookTrigger can include the full
+// message content in the posted event
+type ContentWebhookTrigger struct {
+	Enabled        bool
+	IncludeContent bool
+}
+
+func webhookPath(webhookID int) string {
+	return fmt.Sprintf(
+		"/webhooks/%d",
+		webhookID,
+	)
+}
+
+// ListWebhooks lists the webhooks on the server. An
+// empty messageStream lists those of every stream.
+func (c Client) ListWebhooks(messageStream string) ([]Webhook, error) {
+	return c.ListWebhooksContext(context.Background(), messageStream)
+}
+
+// ListWebhooksContext is ListWebhooks
+// with a caller-supplied context
+func (c Client) ListWebhooksContext(ctx context.Context, messageStream string) ([]Webhook, error) {
+	query := make(url.Values)
+	if messageStream != "" {
+		query.Set("MessageStream", messageStream)
+	}
+
+	var list struct {
+		Webhooks []Webhook
+	}
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodGet,
+			Path:    "/webhooks",
+			Headers: c.serverHeaders(),
+			Query:   query,
+		},
+		&list,
+	)
+	if err != nil {
+		return []Webhook{}, err
+	}
+
+	return list.Webhooks, nil
+}
+
+// GetWebhook retrieves a webhook by ID
+func (c Client) GetWebhook(webhookID int) (Webhook, error) {
+	return c.GetWebhookContext(context.Background(), webhookID)
+}
+
+// GetWebhookContext is GetWebhook
+// with a caller-supplied context
+func (c Client) GetWebhookContext(ctx context.Context, webhookID int) (Webhook, error) {
+	return c.webhookRequest(ctx, http.MethodGet, webhookPath(webhookID), nil)
+}
+
+// CreateWebhook adds a webhook. MessageStream defaults
+// to the server's transactional stream when empty.
+func (c Client) CreateWebhook(webhook Webhook) (Webhook, error) {
+	return c.CreateWebhookContext(context.Background(), webhook)
+}
+
+// CreateWebhookContext is CreateWebhook
+// with a caller-supplied context
+func (c Client) CreateWebhookContext(ctx context.Context, webhook Webhook) (Webhook, error) {
+	if webhook.Url == "" {
+		return Webhook{}, errors.New("webhook Url required")
+	}
+
+	webhook.ID = 0
+	return c.webhookRequest(ctx, http.MethodPost, "/webhooks", webhook)
+}
+
+// EditWebhook replaces the Url, HttpAuth, HttpHeaders and
+// Triggers of a webhook. Its stream cannot be changed.
+func (c Client) EditWebhook(webhookID int, webhook Webhook) (Webhook, error) {
+	return c.EditWebhookContext(context.Background(), webhookID, webhook)
+}
+
+// EditWebhookContext is EditWebhook
+// with a caller-supplied context
+func (c Client) EditWebhookContext(ctx context.Context, webhookID int, webhook Webhook) (Webhook, error) {
+	webhook.ID = 0
+	webhook.MessageStream = ""
+	return c.webhookRequest(ctx, http.MethodPut, webhookPath(webhookID), webhook)
+}
+
+// DeleteWebhook removes a webhook
+func (c Client) DeleteWebhook(webhookID int) error {
+	return c.DeleteWebhookContext(context.Background(), webhookID)
+}
+
+// DeleteWebhookContext is DeleteWebhook
+// with a caller-supplied context
+func (c Client) DeleteWebhookContext(ctx context.Context, webhookID int) error {
+	return c.do(
+		ctx,
+		raw.Request{
+			Method:  http.MethodDelete,
+			Path:    webhookPath(webhookID),
+			Headers: c.serverHeaders(),
+		},
+		nil,
+	)
+}
+
+func (c Client) webhookRequest(ctx context.Context, method, path string, body interface{}) (Webhook, error) {
+	var w Webhook
+	err := c.do(
+		ctx,
+		raw.Request{
+			Method:  method,
+			Path:    path,
+			Headers: c.serverHeaders(),
+			Body:    body,
+		},
+		&w,
+	)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	return w, nil
+}
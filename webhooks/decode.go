@@ -0,0 +1,52 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Decode parses a webhook payload into the event type
+// matching its RecordType: BounceEvent, DeliveryEvent,
+// OpenEvent, ClickEvent, SpamComplaintEvent,
+// SubscriptionChangeEvent or *InboundMessage.
+func Decode(body []byte) (interface{}, error) {
+	var envelope struct {
+		RecordType string
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+
+	switch envelope.RecordType {
+	case RecordTypeBounce:
+		var e BounceEvent
+		err := json.Unmarshal(body, &e)
+		return e, err
+	case RecordTypeDelivery:
+		var e DeliveryEvent
+		err := json.Unmarshal(body, &e)
+		return e, err
+	case RecordTypeOpen:
+		var e OpenEvent
+		err := json.Unmarshal(body, &e)
+		return e, err
+	case RecordTypeClick:
+		var e ClickEvent
+		err := json.Unmarshal(body, &e)
+		return e, err
+	case RecordTypeSpamComplaint:
+		var e SpamComplaintEvent
+		err := json.Unmarshal(body, &e)
+		return e, err
+	case RecordTypeSubscriptionChange:
+		var e SubscriptionChangeEvent
+		err := json.Unmarshal(body, &e)
+		return e, err
+	case RecordTypeInbound, "":
+		m := &InboundMessage{}
+		err := json.Unmarshal(body, m)
+		return m, err
+	default:
+		return nil, fmt.Errorf("unrecognized webhook RecordType %q", envelope.RecordType)
+	}
+}
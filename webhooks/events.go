@@ -0,0 +1,162 @@
+// Package webhooks decodes the events Postmark posts to
+// webhook URLs and serves them through an http.Handler.
+package webhooks
+
+import (
+	"time"
+
+	gostmark "github.com/themartorana/Gostmark/v2"
+)
+
+// Record types Postmark sets on webhook payloads.
+// Inbound messages may arrive without one.
+const (
+	RecordTypeBounce             = "Bounce"
+	RecordTypeDelivery           = "Delivery"
+	RecordTypeOpen               = "Open"
+	RecordTypeClick              = "Click"
+	RecordTypeSpamComplaint      = "SpamComplaint"
+	RecordTypeSubscriptionChange = "SubscriptionChange"
+	RecordTypeInbound            = "Inbound"
+)
+
+// BounceEvent is posted when a message bounces
+type BounceEvent struct {
+	RecordType string
+	gostmark.Bounce
+	Metadata map[string]string
+}
+
+// SpamComplaintEvent is posted when a recipient marks
+// a message as spam. It shares the bounce fields.
+type SpamComplaintEvent struct {
+	RecordType string
+	gostmark.Bounce
+	Metadata map[string]string
+}
+
+// DeliveryEvent is posted when the receiving
+// server accepts a message
+type DeliveryEvent struct {
+	RecordType    string
+	ServerID      int
+	MessageStream string
+	MessageID     string
+	Recipient     string
+	Tag           string
+	DeliveredAt   time.Time
+	Details       string
+	Metadata      map[string]string
+}
+
+// OpenEvent is posted when a recipient opens a
+// message sent with open tracking
+type OpenEvent struct {
+	RecordType    string
+	MessageStream string
+	MessageID     string
+	Recipient     string
+	Tag           string
+	FirstOpen     bool
+	ReceivedAt    time.Time
+	ReadSeconds   int
+	Platform      string
+	UserAgent     string
+	Client        UserAgentInfo
+	OS            UserAgentInfo
+	Geo           Geo
+	Metadata      map[string]string
+}
+
+// ClickEvent is posted when a recipient follows
+// a link in a message sent with link tracking
+type ClickEvent struct {
+	RecordType    string
+	MessageStream string
+	MessageID     string
+	Recipient     string
+	Tag           string
+	ReceivedAt    time.Time
+	ClickLocation string
+	OriginalLink  string
+	Platform      string
+	UserAgent     string
+	Client        UserAgentInfo
+	OS            UserAgentInfo
+	Geo           Geo
+	Metadata      map[string]string
+}
+
+// SubscriptionChangeEvent is posted when a recipient is
+// suppressed or reactivated on a message stream
+type SubscriptionChangeEvent struct {
+	RecordType        string
+	ServerID          int
+	MessageStream     string
+	MessageID         string
+	Recipient         string
+	Tag               string
+	ChangedAt         time.Time
+	Origin            gostmark.SuppressionOrigin
+	SuppressSending   bool
+	SuppressionReason gostmark.SuppressionReason
+	Metadata          map[string]string
+}
+
+// InboundMessage is a message received by the server
+// and posted to its inbound webhook
+type InboundMessage struct {
+	RecordType        string
+	MessageStream     string
+	MessageID         string
+	From              string
+	FromName          string
+	FromFull          gostmark.InboundAddress
+	To                string
+	ToFull            []gostmark.InboundAddress
+	Cc                string
+	CcFull            []gostmark.InboundAddress
+	Bcc               string
+	BccFull           []gostmark.InboundAddress
+	OriginalRecipient string
+	ReplyTo           string
+	Subject           string
+	Date              string
+	MailboxHash       string
+	Tag               string
+	TextBody          string
+	HtmlBody          string
+	StrippedTextReply string
+	Headers           []gostmark.Header
+	Attachments       []InboundAttachment
+}
+
+// InboundAttachment is an attachment on an inbound
+// message. Content is base64 encoded.
+type InboundAttachment struct {
+	Name          string
+	Content       string
+	ContentType   string
+	ContentLength int
+	ContentID     string
+}
+
+// UserAgentInfo identifies the email client or
+// operating system an open or click came from
+type UserAgentInfo struct {
+	Name    string
+	Company string
+	Family  string
+}
+
+// Geo is the approximate location an open or click came from
+type Geo struct {
+	CountryISOCode string
+	Country        string
+	RegionISOCode  string
+	Region         string
+	City           string
+	Zip            string
+	Coords         string
+	IP             string
+}
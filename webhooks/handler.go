@@ -0,0 +1,118 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/subtle"
+	"io/ioutil"
+	"net/http"
+)
+
+// maxPayloadSize bounds a webhook request body. Inbound
+// messages with attachments are the largest payloads.
+const maxPayloadSize = 50 * 1024 * 1024
+
+// Handler receives Postmark webhooks and passes each
+// decoded event to the callback registered for its type.
+// Events without a callback are acknowledged and dropped.
+//
+// A callback returning an error makes the handler answer
+// 500, so Postmark will retry the delivery later. The error
+// itself is not sent back; callbacks should log their own.
+type Handler struct {
+	// Username and Password, when either is set, must
+	// match the HTTP basic auth configured on the webhook
+	Username string
+	Password string
+
+	OnBounce             func(context.Context, BounceEvent) error
+	OnDelivery           func(context.Context, DeliveryEvent) error
+	OnOpen               func(context.Context, OpenEvent) error
+	OnClick              func(context.Context, ClickEvent) error
+	OnSpamComplaint      func(context.Context, SpamComplaintEvent) error
+	OnSubscriptionChange func(context.Context, SubscriptionChangeEvent) error
+	OnInbound            func(context.Context, *InboundMessage) error
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="webhooks"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	event, err := Decode(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.dispatch(r.Context(), event); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// authorized checks the request's basic auth in
+// constant time, when credentials are configured
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.Username == "" && h.Password == "" {
+		return true
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	usernameMatch := subtle.ConstantTimeCompare([]byte(username), []byte(h.Username))
+	passwordMatch := subtle.ConstantTimeCompare([]byte(password), []byte(h.Password))
+	return usernameMatch&passwordMatch == 1
+}
+
+func (h *Handler) dispatch(ctx context.Context, event interface{}) error {
+	switch e := event.(type) {
+	case BounceEvent:
+		if h.OnBounce != nil {
+			return h.OnBounce(ctx, e)
+		}
+	case DeliveryEvent:
+		if h.OnDelivery != nil {
+			return h.OnDelivery(ctx, e)
+		}
+	case OpenEvent:
+		if h.OnOpen != nil {
+			return h.OnOpen(ctx, e)
+		}
+	case ClickEvent:
+		if h.OnClick != nil {
+			return h.OnClick(ctx, e)
+		}
+	case SpamComplaintEvent:
+		if h.OnSpamComplaint != nil {
+			return h.OnSpamComplaint(ctx, e)
+		}
+	case SubscriptionChangeEvent:
+		if h.OnSubscriptionChange != nil {
+			return h.OnSubscriptionChange(ctx, e)
+		}
+	case *InboundMessage:
+		if h.OnInbound != nil {
+			return h.OnInbound(ctx, e)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// spamScoreHeader is added by Postmark's spam filter
+const spamScoreHeader = "X-Spam-Score"
+
+// Header returns the value of the first header with the
+// given name, compared case-insensitively, or ""
+func (m *InboundMessage) Header(name string) string {
+	for _, header := range m.Headers {
+		if strings.EqualFold(header.Name, name) {
+			return header.Value
+		}
+	}
+
+	return ""
+}
+
+// SpamScore returns the score Postmark's spam filter gave
+// the message, and false if the header is absent
+func (m *InboundMessage) SpamScore() (float64, bool) {
+	score, err := strconv.ParseFloat(strings.TrimSpace(m.Header(spamScoreHeader)), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return score, true
+}
+
+// Recipients returns every To and Cc address along
+// with the original recipient, lower cased
+func (m *InboundMessage) Recipients() []string {
+	recipients := make([]string, 0, len(m.ToFull)+len(m.CcFull)+1)
+	if m.OriginalRecipient != "" {
+		recipients = append(recipients, strings.ToLower(m.OriginalRecipient))
+	}
+	for _, address := range m.ToFull {
+		recipients = append(recipients, strings.ToLower(address.Email))
+	}
+	for _, address := range m.CcFull {
+		recipients = append(recipients, strings.ToLower(address.Email))
+	}
+
+	return recipients
+}
+
+// Decode returns the attachment's decoded content
+func (a InboundAttachment) Decode() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(a.Content)
+}
+
+// Reader streams the attachment's decoded content
+func (a InboundAttachment) Reader() io.Reader {
+	return base64.NewDecoder(base64.StdEncoding, strings.NewReader(a.Content))
+}
+
+// InboundHandler processes an inbound message
+type InboundHandler interface {
+	ServeInbound(ctx context.Context, m *InboundMessage) error
+}
+
+// InboundHandlerFunc adapts a function to an InboundHandler
+type InboundHandlerFunc func(ctx context.Context, m *InboundMessage) error
+
+func (f InboundHandlerFunc) ServeInbound(ctx context.Context, m *InboundMessage) error {
+	return f(ctx, m)
+}
+
+// InboundMux routes inbound messages to handlers by
+// MailboxHash or recipient address, much like
+// http.ServeMux routes requests by path.
+//
+// Patterns are matched with path.Match, so "ticket-*"
+// matches any mailbox hash starting with "ticket-" and
+// "*@support.example.com" any support address. Mailbox
+// hash routes are tried before recipient routes, and
+// within each, exact patterns before the others in the
+// order they were registered.
+type InboundMux struct {
+	// SpamThreshold, when above zero, diverts messages
+	// whose X-Spam-Score exceeds it to Spam
+	SpamThreshold float64
+
+	// Spam receives messages over SpamThreshold and
+	// NotFound those no route matched. Either being
+	// nil drops the message.
+	Spam     InboundHandler
+	NotFound InboundHandler
+
+	mu         sync.RWMutex
+	hashes     []inboundRoute
+	recipients []inboundRoute
+}
+
+type inboundRoute struct {
+	pattern string
+	handler InboundHandler
+}
+
+// NewInboundMux returns an empty InboundMux
+func NewInboundMux() *InboundMux {
+	return &InboundMux{}
+}
+
+// HandleMailboxHash routes messages whose MailboxHash
+// matches pattern to handler
+func (mux *InboundMux) HandleMailboxHash(pattern string, handler InboundHandler) {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+
+	mux.hashes = register(mux.hashes, pattern, handler)
+}
+
+// HandleMailboxHashFunc is HandleMailboxHash for a function
+func (mux *InboundMux) HandleMailboxHashFunc(pattern string, handler func(context.Context, *InboundMessage) error) {
+	mux.HandleMailboxHash(pattern, InboundHandlerFunc(handler))
+}
+
+// HandleRecipient routes messages with any recipient
+// matching pattern to handler. Patterns are compared
+// against lower cased addresses.
+func (mux *InboundMux) HandleRecipient(pattern string, handler InboundHandler) {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+
+	mux.recipients = register(mux.recipients, strings.ToLower(pattern), handler)
+}
+
+// HandleRecipientFunc is HandleRecipient for a function
+func (mux *InboundMux) HandleRecipientFunc(pattern string, handler func(context.Context, *InboundMessage) error) {
+	mux.HandleRecipient(pattern, InboundHandlerFunc(handler))
+}
+
+// register adds a route, panicking on invalid or
+// duplicate patterns as http.ServeMux does
+func register(routes []inboundRoute, pattern string, handler InboundHandler) []inboundRoute {
+	if pattern == "" {
+		panic("webhooks: empty inbound pattern")
+	}
+	if handler == nil {
+		panic("webhooks: nil inbound handler")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic("webhooks: invalid inbound pattern " + pattern)
+	}
+	for _, route := range routes {
+		if route.pattern == pattern {
+			panic("webhooks: multiple registrations for " + pattern)
+		}
+	}
+
+	return append(routes, inboundRoute{pattern: pattern, handler: handler})
+}
+
+// Handler returns the handler a message would be routed
+// to, or nil if it would be dropped
+func (mux *InboundMux) Handler(m *InboundMessage) InboundHandler {
+	if mux.SpamThreshold > 0 {
+		if score, ok := m.SpamScore(); ok && score > mux.SpamThreshold {
+			return mux.Spam
+		}
+	}
+
+	mux.mu.RLock()
+	defer mux.mu.RUnlock()
+
+	if m.MailboxHash != "" {
+		if handler := match(mux.hashes, []string{m.MailboxHash}); handler != nil {
+			return handler
+		}
+	}
+	if handler := match(mux.recipients, m.Recipients()); handler != nil {
+		return handler
+	}
+
+	return mux.NotFound
+}
+
+// match finds the route for the first of values an exact
+// pattern matches, then the first a glob pattern matches
+func match(routes []inboundRoute, values []string) InboundHandler {
+	for _, route := range routes {
+		for _, value := range values {
+			if route.pattern == value {
+				return route.handler
+			}
+		}
+	}
+	for _, route := range routes {
+		for _, value := range values {
+			if ok, _ := path.Match(route.pattern, value); ok {
+				return route.handler
+			}
+		}
+	}
+
+	return nil
+}
+
+// ServeInbound routes the message to its handler
+func (mux *InboundMux) ServeInbound(ctx context.Context, m *InboundMessage) error {
+	handler := mux.Handler(m)
+	if handler == nil {
+		return nil
+	}
+
+	return handler.ServeInbound(ctx, m)
+}
+
+// ServeHTTP decodes an inbound webhook and routes it.
+// To require basic auth, set ServeInbound as the
+// OnInbound callback of a Handler instead.
+func (mux *InboundMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h := &Handler{
+		OnInbound: mux.ServeInbound,
+	}
+	h.ServeHTTP(w, r)
+}
@@ -0,0 +1,50 @@
+package webhooks
+
+import (
+	"context"
+
+	gostmark "github.com/themartorana/Gostmark/v2"
+)
+
+// ReplyHandler routes replies to addresses made by a
+// gostmark.ReplyTracker, passing the verified entity ID
+// to OnReply. Register it on an InboundMux, typically
+// for every mailbox hash:
+//
+//	mux.HandleMailboxHash("*", webhooks.ReplyHandler{...})
+type ReplyHandler struct {
+	Tracker gostmark.ReplyTracker
+	OnReply func(ctx context.Context, entityID string, m *InboundMessage) error
+
+	// Invalid receives messages without a validly signed
+	// token. A nil Invalid drops them.
+	Invalid InboundHandler
+}
+
+// ServeInbound verifies the message's mailbox hash, falling
+// back to those of its individual recipients, and calls
+// OnReply with the first entity ID that verifies
+func (h ReplyHandler) ServeInbound(ctx context.Context, m *InboundMessage) error {
+	hashes := make([]string, 0, 1+len(m.ToFull)+len(m.CcFull))
+	hashes = append(hashes, m.MailboxHash)
+	for _, address := range m.ToFull {
+		hashes = append(hashes, address.MailboxHash)
+	}
+	for _, address := range m.CcFull {
+		hashes = append(hashes, address.MailboxHash)
+	}
+
+	for _, hash := range hashes {
+		if hash == "" {
+			continue
+		}
+		if entityID, err := h.Tracker.Verify(hash); err == nil {
+			return h.OnReply(ctx, entityID, m)
+		}
+	}
+
+	if h.Invalid != nil {
+		return h.Invalid.ServeInbound(ctx, m)
+	}
+	return nil
+}